@@ -0,0 +1,155 @@
+package mediainfo
+
+import "sync"
+
+// Analyzer analyzes data as it is written, e.g. a pipe or a recording in progress, using the
+// libmediainfo buffer API. The information found so far is available at any time with Snapshot.
+// An Analyzer is safe for concurrent use, e.g. Snapshot while io.Copy writes to it.
+type Analyzer struct {
+	mu      sync.Mutex
+	mi      *mediaInfo
+	size    int64
+	scan    ScanMode
+	status  bufferStatus
+	written int64 // bytes written
+	next    int64 // offset of the next byte sent to libmediainfo
+	done    bool  // libmediainfo needs no more data
+}
+
+// AnalyzerState is the progress of an Analyzer
+type AnalyzerState struct {
+	Accepted bool    // the format is recognized
+	Filled   bool    // the information of the tracks is available
+	Done     bool    // no more data is needed, the following writes are discarded
+	Progress float64 // from 0 to 1 (MediaInfo_State_Get), 0 if the size is unknown
+	Written  int64   // bytes written
+}
+
+// NewAnalyzer returns an Analyzer of size bytes, -1 if unknown (e.g. a live recording).
+// Finalize or Close must be called to release it.
+func NewAnalyzer(size int64, opts ...Option) (*Analyzer, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	mi, err := newMediaInfo()
+	if err != nil {
+		return nil, err
+	}
+	if err := mi.SetOptions(o); err != nil {
+		mi.Close()
+		return nil, err
+	}
+	mi.OpenBufferInit(size, 0)
+	return &Analyzer{mi: mi, size: size, scan: o.scanMode()}, nil
+}
+
+// Write sends the next bytes p to libmediainfo. When libmediainfo skips forward the data is
+// discarded; when it asks for data written by a previous call, or no more data is needed,
+// the analysis is done and the following writes are discarded.
+// Write implements io.Writer, it always writes len(p) bytes.
+func (a *Analyzer) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.mi == nil {
+		return 0, ErrAnalyzerFinalized
+	}
+
+	start := a.written
+	a.written += int64(len(p))
+	for !a.done && a.next < a.written {
+		b := p[a.next-start:]
+		if len(b) > bufferChunkSize {
+			b = b[:bufferChunkSize]
+		}
+		a.status = a.mi.OpenBufferContinue(b)
+		a.next += int64(len(b))
+		if a.status&bufferFinalized != 0 {
+			a.done = true
+			break
+		}
+		if to := a.mi.OpenBufferGoTo(); to != noSeek {
+			if to < start {
+				// no longer available
+				a.done = true
+				break
+			}
+			a.next = to
+			a.mi.OpenBufferInit(a.size, to)
+		}
+	}
+	return len(p), nil
+}
+
+// State returns the progress of the analysis
+func (a *Analyzer) State() AnalyzerState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := AnalyzerState{
+		Accepted: a.status&bufferAccepted != 0,
+		Filled:   a.status&bufferFilled != 0,
+		Done:     a.done,
+		Written:  a.written,
+	}
+	if a.mi != nil && a.size > 0 {
+		s.Progress = float64(a.mi.State()) / 10000
+	}
+	return s
+}
+
+// Snapshot returns the information found so far, an empty Info if the format is not recognized yet
+func (a *Analyzer) Snapshot() Info {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.mi == nil {
+		return Info{}
+	}
+	info, _ := a.info()
+	return info
+}
+
+// Finalize ends the analysis, releases the Analyzer and returns the final information
+func (a *Analyzer) Finalize() (Info, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.mi == nil {
+		return Info{}, ErrAnalyzerFinalized
+	}
+	defer a.release()
+
+	a.mi.OpenBufferFinalize()
+	return a.info()
+}
+
+// Close releases the Analyzer without finalizing the analysis. Calling Close after Finalize has no effect.
+func (a *Analyzer) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.mi != nil {
+		a.release()
+	}
+	return nil
+}
+
+func (a *Analyzer) release() {
+	a.mi.Close()
+	a.mi = nil
+}
+
+// info returns the information of libmediainfo, ErrUnsupportedFormat if the format is not recognized
+func (a *Analyzer) info() (Info, error) {
+	info, err := a.mi.Inform()
+	if err != nil {
+		return Info{}, err
+	}
+	if !hasFormat(info) {
+		return Info{}, ErrUnsupportedFormat
+	}
+
+	var result Info
+	result.ScanMode = a.scan
+	result.setTracks(info)
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package mediainfo
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnalyzer(t *testing.T) {
+	if _, err := NewAnalyzer(-1); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("NewAnalyzer() error = %v, want %v", err, ErrNotLoaded)
+	}
+
+	if !Load() {
+		t.Fatalf("Failed to load dll/shared object")
+	}
+	defer Unload()
+
+	path := filepath.Join("testdata", "1_video_1_audio_1_menu.mkv")
+	want, err := Inform(path)
+	if err != nil {
+		t.Fatalf("Inform() error = %v", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := NewAnalyzer(-1)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	if got := a.Snapshot(); got.General.Format != "" {
+		t.Errorf("Snapshot() before any write = %+v", got.General)
+	}
+	for len(data) > 0 && !a.State().Done {
+		n := 4096
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := a.Write(data[:n]); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		data = data[n:]
+		if a.State().Accepted && a.Snapshot().General.Format != want.General.Format {
+			t.Errorf("Snapshot() Format = %s, want %s", a.Snapshot().General.Format, want.General.Format)
+		}
+	}
+	if s := a.State(); !s.Accepted || s.Written == 0 {
+		t.Errorf("State() = %+v", s)
+	}
+
+	got, err := a.Finalize()
+	if err != nil {
+		t.Fatalf("Finalize() error = %v", err)
+	}
+	if got.General.Format != want.General.Format || len(got.VideoTracks) != len(want.VideoTracks) ||
+		len(got.AudioTracks) != len(want.AudioTracks) {
+		t.Errorf("Finalize()\nGot \n%+v\nWant\n%+v", got, want)
+	}
+	if _, err := a.Write([]byte{0}); err != ErrAnalyzerFinalized {
+		t.Errorf("Write() after Finalize error = %v, want %v", err, ErrAnalyzerFinalized)
+	}
+	if _, err := a.Finalize(); err != ErrAnalyzerFinalized {
+		t.Errorf("Finalize() twice error = %v, want %v", err, ErrAnalyzerFinalized)
+	}
+	a.Close()
+
+	a, err = NewAnalyzer(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		a.Write([]byte("not media "))
+	}
+	if _, err := a.Finalize(); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Finalize() error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
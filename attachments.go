@@ -0,0 +1,182 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Attachment represents a file embedded in a media file (Matroska attachment, cover art...)
+type Attachment struct {
+	Name        string
+	Description string
+	MIMEType    string
+	Size        int64
+
+	open func() (io.ReadCloser, error)
+}
+
+// Open returns a reader for the attachment content. The caller must close it.
+func (a Attachment) Open() (io.ReadCloser, error) {
+	if a.open == nil {
+		return nil, ErrNoAttachmentData
+	}
+	return a.open()
+}
+
+// Attachments returns the files embedded in the media file at path.
+// Matroska attachments are read natively, any other cover art through libmediainfo
+// (Cover_Data option), which requires Load.
+func Attachments(path string) ([]Attachment, error) {
+	path, _ = filepath.Abs(path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &OpenError{Path: path, Err: unwrapPathError(err)}
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, &OpenError{Path: path, Err: unwrapPathError(err)}
+	}
+
+	if segment, err := matroskaSegment(f, fi.Size()); err == nil {
+		return matroskaAttachments(f, path, segment, fi.Size())
+	}
+	return coverAttachments(path)
+}
+
+// matroskaAttachments reads the Attachments element of segment.
+// Attachment content is read lazily from path.
+func matroskaAttachments(r io.ReaderAt, path string, segment ebmlElement, size int64) ([]Attachment, error) {
+	off, err := matroskaTopLevel(r, segment, size, mkvIDAttachments)
+	if err != nil || off < 0 {
+		return nil, err
+	}
+	attachments, err := readEBMLElement(r, off)
+	if err != nil {
+		return nil, err
+	}
+	if attachments.ID != mkvIDAttachments {
+		return nil, errInvalidEBML
+	}
+
+	var result []Attachment
+	err = ebmlChildren(r, attachments.DataOffset, attachments.end(size), func(file ebmlElement) (bool, error) {
+		if file.ID != mkvIDAttachedFile {
+			return true, nil
+		}
+
+		a := Attachment{}
+		var dataOffset int64
+		err := ebmlChildren(r, file.DataOffset, file.end(size), func(e ebmlElement) (bool, error) {
+			var err error
+			switch e.ID {
+			case mkvIDFileName:
+				a.Name, err = readEBMLString(r, e)
+			case mkvIDFileMimeType:
+				a.MIMEType, err = readEBMLString(r, e)
+			case mkvIDFileDesc:
+				a.Description, err = readEBMLString(r, e)
+			case mkvIDFileData:
+				// data of unknown size ends with the attached file
+				dataOffset, a.Size = e.DataOffset, e.end(file.end(size))-e.DataOffset
+			}
+			return true, err
+		})
+		if err != nil {
+			return false, err
+		}
+
+		a.open = func() (io.ReadCloser, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			return sectionReadCloser{io.NewSectionReader(f, dataOffset, a.Size), f}, nil
+		}
+		result = append(result, a)
+		return true, nil
+	})
+	return result, err
+}
+
+// sectionReadCloser closes the file behind a section reader
+type sectionReadCloser struct {
+	*io.SectionReader
+	io.Closer
+}
+
+// coverExtensions are the file extensions used to name covers by MIME type
+var coverExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/bmp":  ".bmp",
+	"image/webp": ".webp",
+}
+
+// coverAttachments returns the covers reported by libmediainfo in the General track
+func coverAttachments(path string) ([]Attachment, error) {
+	o, err := newOptions([]Option{WithCoverData("base64")})
+	if err != nil {
+		return nil, err
+	}
+	info, err := informFile(path, o)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Attachment
+	for _, track := range info.Media.Tracks {
+		if track.Type != "General" || track.CoverData == "" {
+			continue
+		}
+
+		// multiple covers are separated by " / "
+		data := strings.Split(track.CoverData, " / ")
+		mimes := strings.Split(track.CoverMime, " / ")
+		types := strings.Split(track.CoverType, " / ")
+		descriptions := strings.Split(track.CoverDescription, " / ")
+
+		for i, d := range data {
+			b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(d))
+			if err != nil {
+				return nil, newParseError([]byte(d), err)
+			}
+
+			a := Attachment{
+				Name:        "cover",
+				MIMEType:    element(mimes, i),
+				Description: element(descriptions, i),
+				Size:        int64(len(b)),
+				open: func() (io.ReadCloser, error) {
+					return ioutil.NopCloser(bytes.NewReader(b)), nil
+				},
+			}
+			if a.Description == "" {
+				a.Description = element(types, i)
+			}
+			if i > 0 {
+				a.Name += strconv.Itoa(i + 1)
+			}
+			a.Name += coverExtensions[a.MIMEType]
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// element returns s[i] or "" if out of range
+func element(s []string, i int) string {
+	if i < len(s) {
+		return strings.TrimSpace(s[i])
+	}
+	return ""
+}
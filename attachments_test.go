@@ -0,0 +1,136 @@
+package mediainfo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ebml encodes an element with id and data
+func ebml(id uint32, data ...[]byte) []byte {
+	b := []byte{}
+	for shift := 24; shift >= 0; shift -= 8 {
+		if c := byte(id >> uint(shift)); c != 0 || len(b) > 0 {
+			b = append(b, c)
+		}
+	}
+	content := bytes.Join(data, nil)
+	size := uint64(len(content))
+	// 8 bytes size
+	b = append(b, 0x01, byte(size>>48), byte(size>>40), byte(size>>32), byte(size>>24), byte(size>>16), byte(size>>8), byte(size))
+	return append(b, content...)
+}
+
+func TestAttachmentsMatroska(t *testing.T) {
+	cover := []byte("\xff\xd8\xffnot really a jpeg")
+	font := []byte("OTTO not really a font")
+
+	attachments := ebml(mkvIDAttachments,
+		ebml(mkvIDAttachedFile,
+			ebml(mkvIDFileName, []byte("cover.jpg")),
+			ebml(mkvIDFileMimeType, []byte("image/jpeg")),
+			ebml(mkvIDFileData, cover),
+		),
+		ebml(mkvIDAttachedFile,
+			ebml(mkvIDFileDesc, []byte("Subtitle font")),
+			ebml(mkvIDFileName, []byte("font.otf")),
+			ebml(mkvIDFileMimeType, []byte("font/otf\x00\x00")),
+			ebml(mkvIDFileData, font),
+		),
+	)
+	void := ebml(0xEC, make([]byte, 100))
+	// SeekHead pointing to the attachments after a Void element
+	seekHeadLen := len(ebml(mkvIDSeekHead, ebml(mkvIDSeek, ebml(mkvIDSeekID, []byte{0, 0, 0, 0}), ebml(mkvIDSeekPosition, []byte{0}))))
+	seekHead := ebml(mkvIDSeekHead, ebml(mkvIDSeek,
+		ebml(mkvIDSeekID, []byte{0x19, 0x41, 0xA4, 0x69}),
+		ebml(mkvIDSeekPosition, []byte{byte(seekHeadLen + len(void))}),
+	))
+	file := append(ebml(ebmlIDHeader, ebml(0x4282, []byte("matroska"))), ebml(mkvIDSegment, seekHead, void, attachments)...)
+
+	dir, err := ioutil.TempDir("", "mediainfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "attachments.mkv")
+	if err := ioutil.WriteFile(path, file, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Attachments(path)
+	if err != nil {
+		t.Fatalf("Attachments() error = %v", err)
+	}
+	want := []struct {
+		name, description, mime string
+		data                    []byte
+	}{
+		{name: "cover.jpg", mime: "image/jpeg", data: cover},
+		{name: "font.otf", description: "Subtitle font", mime: "font/otf", data: font},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Attachments() = %+v, want %d attachments", got, len(want))
+	}
+	for i, w := range want {
+		a := got[i]
+		if a.Name != w.name || a.Description != w.description || a.MIMEType != w.mime || a.Size != int64(len(w.data)) {
+			t.Errorf("Attachments()[%d] = %+v, want %+v", i, a, w)
+		}
+		rc, err := a.Open()
+		if err != nil {
+			t.Fatalf("Attachment.Open() error = %v", err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil || !bytes.Equal(b, w.data) {
+			t.Errorf("Attachment.Open() = %q, %v, want %q", b, err, w.data)
+		}
+	}
+}
+
+func TestAttachmentsNone(t *testing.T) {
+	got, err := Attachments(filepath.Join("testdata", "1_video_1_audio_1_menu.mkv"))
+	if err != nil || len(got) != 0 {
+		t.Errorf("Attachments() = %v, %v, want no attachments", got, err)
+	}
+}
+
+func Test_matroskaSeek_unknownSize(t *testing.T) {
+	// SeekID of unknown size
+	seekHead := ebml(mkvIDSeekHead, ebml(mkvIDSeek, []byte{0x53, 0xAB, 0xFF, 0x01}))
+	r := bytes.NewReader(seekHead)
+	e, err := readEBMLElement(r, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := matroskaSeek(r, e, int64(len(seekHead)), mkvIDAttachments); err != errInvalidEBML {
+		t.Errorf("matroskaSeek() error = %v, want %v", err, errInvalidEBML)
+	}
+}
+
+func Test_matroskaAttachments_unknownSize(t *testing.T) {
+	// FileData of unknown size, followed by another attached file
+	data := []byte("font data")
+	unknown := append([]byte{0x46, 0x5C, 0xFF}, data...)
+	file := append(ebml(ebmlIDHeader, ebml(0x4282, []byte("matroska"))), ebml(mkvIDSegment,
+		ebml(mkvIDAttachments,
+			ebml(mkvIDAttachedFile, ebml(mkvIDFileName, []byte("font.otf")), unknown),
+			ebml(mkvIDAttachedFile, ebml(mkvIDFileName, []byte("cover.jpg"))),
+		),
+	)...)
+	r := bytes.NewReader(file)
+	segment, err := matroskaSegment(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := matroskaAttachments(r, "attachments.mkv", segment, r.Size())
+	if err != nil || len(got) != 2 || got[0].Size != int64(len(data)) {
+		t.Errorf("matroskaAttachments() = %+v, %v, want a first attachment of %d bytes", got, err, len(data))
+	}
+
+	if _, err := (Attachment{}).Open(); err != ErrNoAttachmentData {
+		t.Errorf("Attachment{}.Open() error = %v, want %v", err, ErrNoAttachmentData)
+	}
+}
@@ -0,0 +1,147 @@
+package mediainfo
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// errNotAudioFormat is returned by an audio probe when the data is not in its format
+var errNotAudioFormat = errors.New("not in the probed audio format")
+
+// audioProbe reads the General and Audio information of an audio-only file
+type audioProbe func(r io.ReaderAt, size int64) (Info, error)
+
+// audioProbes are the native parsers of InformAudio, the ones with the most reliable magic first
+var audioProbes = []audioProbe{probeFLAC, probeWAV, probeOgg, probeADTS, probeMP3}
+
+// InformAudio analyzes an audio-only file (MP3, FLAC, WAV/BWF, Ogg Opus/Vorbis, AAC ADTS)
+// without libmediainfo. It is faster than Inform as only the headers are read
+// (the whole file for ADTS which has no duration header). Inform uses it with WithNativeAudio.
+func InformAudio(path string) (Info, error) {
+	path, _ = filepath.Abs(path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, &OpenError{Path: path, Err: unwrapPathError(err)}
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return Info{}, &OpenError{Path: path, Err: unwrapPathError(err)}
+	}
+
+	for _, probe := range audioProbes {
+		info, err := probe(f, fi.Size())
+		if err == errNotAudioFormat {
+			continue
+		}
+		if err != nil {
+			return Info{}, &OpenError{Path: path, Err: err}
+		}
+		info.General.CompleteName = path
+		info.General.FileExtension = strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+		return info, nil
+	}
+	return Info{}, &OpenError{Path: path, Err: ErrUnsupportedFormat}
+}
+
+// newAudioInfo returns the Info of a file of size bytes with a single audio track a
+func newAudioInfo(format string, size int64, a Audio, tags Tags) Info {
+	info := Info{
+		General: General{
+			AudioCount: 1,
+			Format:     format,
+			FileSize:   uint(size),
+			Duration:   a.Duration,
+			Title:      tags.TrackName,
+			Tags:       tags,
+		},
+		AudioTracks: []Audio{a},
+	}
+	if a.Duration > 0 {
+		info.General.OverallBitRate = float32(float64(size) * 8 / float64(a.Duration))
+	}
+	return info
+}
+
+// readAt reads n bytes at off, io.ErrUnexpectedEOF if the data is shorter
+func readAt(r io.ReaderAt, off int64, n int) ([]byte, error) {
+	b := make([]byte, n)
+	read, err := r.ReadAt(b, off)
+	if read == n {
+		return b, nil
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return b[:read], err
+}
+
+// readChunk reads the n bytes at off of a file of size bytes, io.ErrUnexpectedEOF if they are past
+// the end of the file or more than max
+func readChunk(r io.ReaderAt, off, n, size, max int64) ([]byte, error) {
+	if n < 0 || n > max || n > size-off {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return readAt(r, off, int(n))
+}
+
+// tagFieldSetters set the Tags fields by Vorbis comment name, other names go to Custom
+var tagFieldSetters = map[string]func(t *Tags, v string){
+	"TITLE":       func(t *Tags, v string) { t.TrackName = v },
+	"ALBUM":       func(t *Tags, v string) { t.Album = v },
+	"ARTIST":      func(t *Tags, v string) { t.Performer = v },
+	"ALBUMARTIST": func(t *Tags, v string) { t.AlbumPerformer = v },
+	"COMPOSER":    func(t *Tags, v string) { t.Composer = v },
+	"PUBLISHER":   func(t *Tags, v string) { t.Publisher = v },
+	"GENRE":       func(t *Tags, v string) { t.Genre = v },
+	"DATE":        func(t *Tags, v string) { t.RecordedDate = v },
+	"COMMENT":     func(t *Tags, v string) { t.Comment = v },
+	"DESCRIPTION": func(t *Tags, v string) { t.Description = v },
+	"COPYRIGHT":   func(t *Tags, v string) { t.Copyright = v },
+	"TRACKNUMBER": func(t *Tags, v string) { setPosition(&t.TrackPosition, &t.TrackTotal, v) },
+	"TRACKTOTAL":  func(t *Tags, v string) { t.TrackTotal = toUint(v) },
+	"TOTALTRACKS": func(t *Tags, v string) { t.TrackTotal = toUint(v) },
+	"DISCNUMBER":  func(t *Tags, v string) { setPosition(&t.PartPosition, &t.PartTotal, v) },
+	"DISCTOTAL":   func(t *Tags, v string) { t.PartTotal = toUint(v) },
+	"TOTALDISCS":  func(t *Tags, v string) { t.PartTotal = toUint(v) },
+}
+
+// setTag sets the tag name (a Vorbis comment name, case insensitive) to v
+func setTag(t *Tags, name, v string) {
+	if set, ok := tagFieldSetters[strings.ToUpper(name)]; ok {
+		set(t, v)
+		return
+	}
+	if t.Custom == nil {
+		t.Custom = make(map[string]string)
+	}
+	t.Custom[name] = v
+}
+
+// setPosition sets position and total from "3" or "3/12"
+func setPosition(position, total *uint, v string) {
+	parts := strings.SplitN(v, "/", 2)
+	if n, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 64); err == nil {
+		*position = uint(n)
+	}
+	if len(parts) == 2 {
+		if n, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64); err == nil {
+			*total = uint(n)
+		}
+	}
+}
+
+// latin1 decodes ISO 8859-1 text
+func latin1(b []byte) string {
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
@@ -0,0 +1,388 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// id3v2Frame returns an ID3v2.3 text frame
+func id3v2Frame(id string, text ...byte) []byte {
+	f := []byte(id)
+	f = append(f, 0, 0, 0, byte(len(text)), 0, 0)
+	return append(f, text...)
+}
+
+// testID3v2 returns an ID3v2.3 tag with a title, track, comment, user defined and genre frames
+func testID3v2() []byte {
+	var frames []byte
+	frames = append(frames, id3v2Frame("TIT2", append([]byte{0}, "Song"...)...)...)
+	frames = append(frames, id3v2Frame("TRCK", append([]byte{0}, "3/12"...)...)...)
+	frames = append(frames, id3v2Frame("TCON", append([]byte{0}, "(17)"...)...)...)
+	// UTF-16 with BOM
+	frames = append(frames, id3v2Frame("TPE1", 1, 0xFF, 0xFE, 'A', 0, 'r', 0, 't', 0, 0xE9, 0)...)
+	frames = append(frames, id3v2Frame("COMM", append([]byte{0, 'e', 'n', 'g', 0}, "Nice"...)...)...)
+	frames = append(frames, id3v2Frame("TXXX", append([]byte{3}, "MOOD\x00Calm"...)...)...)
+	frames = append(frames, make([]byte, 10)...) // padding
+	n := len(frames)
+	h := []byte{'I', 'D', '3', 3, 0, 0, byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+	return append(h, frames...)
+}
+
+// testID3v1 returns an ID3v1.1 tag
+func testID3v1() []byte {
+	b := make([]byte, 128)
+	copy(b, "TAG")
+	copy(b[3:], "Old title")
+	copy(b[63:], "Album")
+	copy(b[93:], "1999")
+	b[126] = 7
+	b[127] = 255
+	return b
+}
+
+// testMP3 returns a MPEG-1 Layer 3 128 kb/s 44.1 kHz stereo file, the first frame with a Xing header of frames
+func testMP3(frames uint32) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	copy(frame[36:], "Xing\x00\x00\x00\x01")
+	binary.BigEndian.PutUint32(frame[44:], frames)
+	copy(frame[48:], "LAME3.100")
+
+	b := testID3v2()
+	b = append(b, frame...)
+	for i := 0; i < 2; i++ {
+		b = append(b, 0xFF, 0xFB, 0x90, 0x00)
+		b = append(b, make([]byte, 413)...)
+	}
+	return append(b, testID3v1()...)
+}
+
+// testADTS returns frames AAC LC 44.1 kHz stereo ADTS frames of 100 bytes
+func testADTS(frames int) []byte {
+	const size = 100
+	var b []byte
+	for i := 0; i < frames; i++ {
+		b = append(b, 0xFF, 0xF1, 0x50, 0x80, byte(size>>3), byte(size&7)<<5|0x1F, 0xFC)
+		b = append(b, make([]byte, size-7)...)
+	}
+	return b
+}
+
+// testFLAC returns a 10s 44.1 kHz stereo 16 bits FLAC file with Vorbis comments
+func testFLAC() []byte {
+	info := make([]byte, 34)
+	rate, channels, bits, samples := uint64(44100), uint64(2), uint64(16), uint64(441000)
+	binary.BigEndian.PutUint64(info[10:], rate<<44|(channels-1)<<41|(bits-1)<<36|samples)
+
+	comments := []byte{}
+	add := func(s string) {
+		comments = append(comments, byte(len(s)), 0, 0, 0)
+		comments = append(comments, s...)
+	}
+	add("reference libFLAC 1.3.3 20190804")
+	comments = append(comments, 3, 0, 0, 0)
+	add("TITLE=Track")
+	add("tracknumber=2")
+	add("REPLAYGAIN_TRACK_GAIN=-6.5 dB")
+
+	b := []byte("fLaC")
+	b = append(b, flacStreamInfo, 0, 0, 34)
+	b = append(b, info...)
+	b = append(b, 0x80|flacVorbisComment, 0, 0, byte(len(comments)))
+	b = append(b, comments...)
+	return append(b, make([]byte, 1000)...)
+}
+
+// testWAV returns a 1s 48 kHz stereo 24 bits Broadcast Wave file
+func testWAV() []byte {
+	chunk := func(id string, data []byte) []byte {
+		c := append([]byte(id), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(c[4:], uint32(len(data)))
+		c = append(c, data...)
+		if len(data)%2 == 1 {
+			c = append(c, 0)
+		}
+		return c
+	}
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk, 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:], 2)
+	binary.LittleEndian.PutUint32(fmtChunk[4:], 48000)
+	binary.LittleEndian.PutUint32(fmtChunk[8:], 288000)
+	binary.LittleEndian.PutUint16(fmtChunk[12:], 6)
+	binary.LittleEndian.PutUint16(fmtChunk[14:], 24)
+
+	bext := make([]byte, 602)
+	copy(bext, "Interview")
+	copy(bext[320:], "2021-03-04")
+	copy(bext[330:], "10:20:30")
+
+	info := []byte("INFO")
+	info = append(info, chunk("INAM", []byte("Take 1\x00"))...)
+
+	b := []byte("RIFF\x00\x00\x00\x00WAVE")
+	b = append(b, chunk("fmt ", fmtChunk)...)
+	b = append(b, chunk("bext", bext)...)
+	b = append(b, chunk("LIST", info)...)
+	b = append(b, chunk("data", make([]byte, 288000))...)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(b)-8))
+	return b
+}
+
+// testOpus returns an Ogg Opus stream of 2s, pre-skip 312
+func testOpus() []byte {
+	page := func(granule int64, seq uint32, packet []byte) []byte {
+		p := make([]byte, 26)
+		copy(p, "OggS")
+		binary.LittleEndian.PutUint64(p[6:], uint64(granule))
+		binary.LittleEndian.PutUint32(p[14:], 1)
+		binary.LittleEndian.PutUint32(p[18:], seq)
+		var lacing []byte
+		n := len(packet)
+		for ; n >= 255; n -= 255 {
+			lacing = append(lacing, 255)
+		}
+		lacing = append(lacing, byte(n))
+		p = append(p, byte(len(lacing)))
+		p = append(p, lacing...)
+		return append(p, packet...)
+	}
+	head := []byte("OpusHead\x01\x02\x38\x01\x80\xBB\x00\x00\x00\x00\x00")
+	tags := []byte("OpusTags")
+	tags = append(tags, 11, 0, 0, 0)
+	tags = append(tags, "libopus 1.3"...)
+	tags = append(tags, 1, 0, 0, 0, 12, 0, 0, 0)
+	tags = append(tags, "ARTIST=Band"...)
+	tags = append(tags, '!')
+	// a comment header spanning several segments
+	tags = append(tags, make([]byte, 300)...)
+
+	b := page(0, 0, head)
+	b = append(b, page(0, 1, tags)...)
+	return append(b, page(2*48000+312, 2, make([]byte, 500))...)
+}
+
+func TestInformAudio(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-audio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		general   General
+		audio     Audio
+		checkTags func(t *testing.T, tags Tags)
+	}{
+		{
+			name:    "song.MP3",
+			data:    testMP3(100),
+			general: General{Format: "MPEG Audio", EncodedLibrary: "LAME3.100", Title: "Song"},
+			audio: Audio{Format: "MPEG Audio", FormatProfile: "Layer 3", Channels: 2, SamplingRate: 44100,
+				SamplesPerFrame: 1152, FrameCount: 100, SamplingCount: 115200, Duration: 115200.0 / 44100, CompressionMode: "Lossy"},
+			checkTags: func(t *testing.T, tags Tags) {
+				want := Tags{TrackName: "Song", TrackPosition: 3, TrackTotal: 12, Genre: "Rock", Performer: "Arté",
+					Comment: "Nice", Album: "Album", RecordedDate: "1999", Custom: map[string]string{"MOOD": "Calm"}}
+				if !reflect.DeepEqual(tags, want) {
+					t.Errorf("Tags = %+v, want %+v", tags, want)
+				}
+			},
+		},
+		{
+			name:    "song.aac",
+			data:    testADTS(43),
+			general: General{Format: "ADTS"},
+			audio: Audio{Format: "AAC", FormatAdditionalFeatures: "LC", Channels: 2, SamplingRate: 44100,
+				SamplesPerFrame: 1024, FrameCount: 43, SamplingCount: 44032, Duration: 44032.0 / 44100, CompressionMode: "Lossy"},
+		},
+		{
+			name:    "song.flac",
+			data:    testFLAC(),
+			general: General{Format: "FLAC", EncodedLibrary: "reference libFLAC 1.3.3 20190804", Title: "Track"},
+			audio: Audio{Format: "FLAC", Channels: 2, SamplingRate: 44100, BitDepth: 16, SamplingCount: 441000,
+				Duration: 10, CompressionMode: "Lossless"},
+			checkTags: func(t *testing.T, tags Tags) {
+				want := Tags{TrackName: "Track", TrackPosition: 2, Custom: map[string]string{"REPLAYGAIN_TRACK_GAIN": "-6.5 dB"}}
+				if !reflect.DeepEqual(tags, want) {
+					t.Errorf("Tags = %+v, want %+v", tags, want)
+				}
+			},
+		},
+		{
+			name: "take.wav",
+			data: testWAV(),
+			general: General{Format: "Wave", Title: "Take 1",
+				EncodedDate: time.Date(2021, 3, 4, 10, 20, 30, 0, time.UTC)},
+			audio: Audio{Format: "PCM", CodecID: "1", Channels: 2, SamplingRate: 48000, BitDepth: 24, SamplingCount: 48000,
+				Duration: 1, BitRate: 2304000, CompressionMode: "Lossless", StreamSize: 288000},
+			checkTags: func(t *testing.T, tags Tags) {
+				if tags.Description != "Interview" || tags.TrackName != "Take 1" {
+					t.Errorf("Tags = %+v", tags)
+				}
+			},
+		},
+		{
+			name:    "voice.opus",
+			data:    testOpus(),
+			general: General{Format: "Ogg", EncodedLibrary: "libopus 1.3"},
+			audio:   Audio{Format: "Opus", Channels: 2, SamplingRate: 48000, SamplingCount: 96000, Duration: 2, CompressionMode: "Lossy"},
+			checkTags: func(t *testing.T, tags Tags) {
+				if tags.Performer != "Band!" {
+					t.Errorf("Performer = %q, want Band!", tags.Performer)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := ioutil.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			info, err := InformAudio(path)
+			if err != nil {
+				t.Fatalf("InformAudio() error = %v", err)
+			}
+
+			g := info.General
+			if g.CompleteName != path || g.FileSize != uint(len(tt.data)) || g.AudioCount != 1 || g.Duration != tt.audio.Duration {
+				t.Errorf("General = %+v", g)
+			}
+			if g.Format != tt.general.Format || g.EncodedLibrary != tt.general.EncodedLibrary ||
+				g.Title != tt.general.Title || !g.EncodedDate.Equal(tt.general.EncodedDate) {
+				t.Errorf("General = %+v, want %+v", g, tt.general)
+			}
+
+			if len(info.AudioTracks) != 1 {
+				t.Fatalf("AudioTracks = %+v", info.AudioTracks)
+			}
+			a := info.AudioTracks[0]
+			want := tt.audio
+			if want.BitRate == 0 {
+				want.BitRate = a.BitRate
+			}
+			if want.StreamSize == 0 {
+				want.StreamSize = a.StreamSize
+			}
+			want.Tags = a.Tags
+			if !reflect.DeepEqual(a, want) {
+				t.Errorf("Audio\nGot \n%+v\nWant\n%+v", a, want)
+			}
+			if tt.checkTags != nil {
+				tt.checkTags(t, g.Tags)
+			}
+		})
+	}
+}
+
+func TestInformAudio_Unsupported(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-audio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "text.mp3")
+	ioutil.WriteFile(path, bytes.Repeat([]byte("not audio "), 100), 0644)
+	if _, err := InformAudio(path); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("InformAudio() error = %v, want ErrUnsupportedFormat", err)
+	}
+	if _, err := InformAudio(filepath.Join(dir, "missing.mp3")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("InformAudio() error = %v, want not exist", err)
+	}
+}
+
+func TestInform_nativeAudio(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-audio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	song := filepath.Join(dir, "song.mp3")
+	text := filepath.Join(dir, "text.mp3")
+	ioutil.WriteFile(song, testMP3(100), 0644)
+	ioutil.WriteFile(text, bytes.Repeat([]byte("not audio "), 100), 0644)
+
+	// read without libmediainfo, exact values
+	info, err := Inform(song, WithNativeAudio())
+	if err != nil {
+		t.Fatalf("Inform() error = %v", err)
+	}
+	if info.General.Format != "MPEG Audio" || info.General.CompleteName != song || info.ScanMode != ScanNative {
+		t.Errorf("Inform() = %+v, %v", info.General, info.ScanMode)
+	}
+	if got := info.Estimated(); got != nil {
+		t.Errorf("Info.Estimated() = %v, want nil", got)
+	}
+
+	// the other files are analyzed by libmediainfo
+	if _, err := Inform(text, WithNativeAudio()); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("Inform() error = %v, want %v", err, ErrNotLoaded)
+	}
+}
+
+func Test_probeWAV_malformed(t *testing.T) {
+	tests := []struct {
+		name         string
+		modify       func(b []byte)
+		wantErr      bool
+		wantSamples  uint
+		wantBitDepth uint
+	}{
+		{name: "24 bits", wantSamples: 48000, wantBitDepth: 24},
+		{name: "4 bits", modify: func(b []byte) {
+			binary.LittleEndian.PutUint16(b[32:], 1)
+			binary.LittleEndian.PutUint16(b[34:], 4)
+		}, wantSamples: 288000, wantBitDepth: 4},
+		{name: "12 bits", modify: func(b []byte) {
+			binary.LittleEndian.PutUint16(b[32:], 4)
+			binary.LittleEndian.PutUint16(b[34:], 12)
+		}, wantSamples: 72000, wantBitDepth: 12},
+		{name: "no block align", modify: func(b []byte) {
+			binary.LittleEndian.PutUint16(b[32:], 0)
+		}, wantBitDepth: 24},
+		{name: "bext past the end", modify: func(b []byte) {
+			binary.LittleEndian.PutUint32(b[40:], 0xFFFFFFF0)
+		}, wantErr: true},
+		{name: "fmt past the end", modify: func(b []byte) {
+			binary.LittleEndian.PutUint32(b[16:], 0x7FFFFFF0)
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testWAV()
+			if tt.modify != nil {
+				tt.modify(b)
+			}
+			info, err := probeWAV(bytes.NewReader(b), int64(len(b)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeWAV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if a := info.AudioTracks[0]; a.SamplingCount != tt.wantSamples || a.BitDepth != tt.wantBitDepth {
+				t.Errorf("probeWAV() SamplingCount = %d, BitDepth = %d, want %d, %d", a.SamplingCount, a.BitDepth, tt.wantSamples, tt.wantBitDepth)
+			}
+		})
+	}
+}
+
+func Test_readID3v2_truncated(t *testing.T) {
+	tag := testID3v2()
+	if _, err := readID3v2(bytes.NewReader(tag), int64(len(tag))-1); err == nil {
+		t.Errorf("readID3v2() of a tag larger than the file, want error")
+	}
+	if tags, err := readID3v2(bytes.NewReader(tag), int64(len(tag))); err != nil || tags.TrackName != "Song" {
+		t.Errorf("readID3v2() = %+v, %v", tags, err)
+	}
+}
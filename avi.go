@@ -0,0 +1,437 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// aviMaxHeaderList is the size limit of the hdrl and INFO lists
+	aviMaxHeaderList = 16 << 20
+	// aviInterleaveScan is the size of the movi list read to check the interleaving
+	aviInterleaveScan = 4 << 20
+	// aviMaxInterleave is the longest duration of consecutive chunks of one stream in an interleaved file, in seconds
+	aviMaxInterleave = 1.0
+)
+
+// errNotAVI is returned when data is not an AVI file
+var errNotAVI = errors.New("not an AVI file")
+
+// aviVideoFormats maps the video FourCC (upper case) to libmediainfo formats
+var aviVideoFormats = map[string]string{
+	"XVID": "MPEG-4 Visual",
+	"DIVX": "MPEG-4 Visual",
+	"DX50": "MPEG-4 Visual",
+	"FMP4": "MPEG-4 Visual",
+	"MP4V": "MPEG-4 Visual",
+	"DIV3": "MS-MPEG4 v3",
+	"MP43": "MS-MPEG4 v3",
+	"H264": "AVC",
+	"X264": "AVC",
+	"AVC1": "AVC",
+	"HEVC": "HEVC",
+	"MJPG": "JPEG",
+	"DVSD": "DV",
+	"DV25": "DV",
+	"DV50": "DVCPRO 50",
+	"CDVC": "DV",
+	"MPG1": "MPEG Video",
+	"MPG2": "MPEG Video",
+	"WMV3": "VC-1",
+	"WVC1": "VC-1",
+	"HFYU": "HuffYUV",
+	"FFV1": "FFV1",
+	"UYVY": "YUV",
+	"YUY2": "YUV",
+	"":     "RGB",
+}
+
+// aviStream is a stream of the hdrl list
+type aviStream struct {
+	kind    string // strh fccType: vids, auds, txts or iavs (DV type 1)
+	handler string
+	scale   uint32
+	rate    uint32
+	length  uint32
+	format  []byte // strf
+	name    string // strn
+}
+
+// duration returns the stream duration in seconds from its strh header
+func (s aviStream) duration() float64 {
+	if s.rate == 0 {
+		return 0
+	}
+	return float64(s.length) * float64(s.scale) / float64(s.rate)
+}
+
+// aviFile is the state of readAVI
+type aviFile struct {
+	streams     []aviStream
+	totalFrames uint32 // avih, or dmlh for OpenDML
+	width       uint32
+	height      uint32
+	openDML     bool
+	tags        Tags
+	software    string
+	moviOffset  int64
+	moviSize    int64
+}
+
+// InformAVI analyzes an AVI file without libmediainfo: the stream headers (avih, strh, strf, strn),
+// the OpenDML extension and the INFO list are read and the interleaving of the first chunks is checked.
+// Only header level information is available, e.g. the stream sizes are unknown.
+func InformAVI(path string) (Info, error) {
+	path, _ = filepath.Abs(path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, &OpenError{Path: path, Err: unwrapPathError(err)}
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return Info{}, &OpenError{Path: path, Err: unwrapPathError(err)}
+	}
+
+	info, err := readAVI(f, fi.Size())
+	if err == errNotAVI {
+		return Info{}, &OpenError{Path: path, Err: ErrUnsupportedFormat}
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	info.General.CompleteName = path
+	info.General.FileExtension = strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	return info, nil
+}
+
+// readAVI reads the AVI file r of size bytes
+func readAVI(r io.ReaderAt, size int64) (Info, error) {
+	h, err := readAt(r, 0, 12)
+	if err != nil || string(h[:4]) != "RIFF" || string(h[8:12]) != "AVI " {
+		return Info{}, errNotAVI
+	}
+
+	var avi aviFile
+	// the first RIFF AVI is followed by RIFF AVIX in OpenDML files
+	for off := int64(0); off+12 <= size; {
+		h, err := readAt(r, off, 12)
+		if err != nil {
+			return Info{}, err
+		}
+		if string(h[:4]) != "RIFF" {
+			break
+		}
+		n := int64(binary.LittleEndian.Uint32(h[4:]))
+		if off > 0 {
+			avi.openDML = true
+		} else if err := avi.readChunks(r, 12, min64(8+n, size)); err != nil {
+			return Info{}, err
+		}
+		off += 8 + n + n&1
+	}
+	if len(avi.streams) == 0 {
+		return Info{}, errNotAVI
+	}
+
+	info := avi.info(size)
+	notInterleaved, err := avi.checkInterleave(r)
+	if err != nil {
+		return Info{}, err
+	}
+	info.General.NotInterleaved = notInterleaved
+	return info, nil
+}
+
+// min64 returns the smaller of a and b
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// readChunks reads the top level chunks of the RIFF AVI between off and end
+func (avi *aviFile) readChunks(r io.ReaderAt, off, end int64) error {
+	for off+12 <= end {
+		h, err := readAt(r, off, 12)
+		if err != nil {
+			return err
+		}
+		id, n := string(h[:4]), int64(binary.LittleEndian.Uint32(h[4:]))
+		if off+8+n > end {
+			// truncated file
+			n = end - off - 8
+		}
+		if id == "LIST" {
+			switch string(h[8:12]) {
+			case "movi":
+				avi.moviOffset, avi.moviSize = off+12, n-4
+			case "hdrl", "INFO":
+				if n > aviMaxHeaderList {
+					return errNotAVI
+				}
+				b, err := readAt(r, off+8, int(n))
+				if err != nil {
+					return err
+				}
+				avi.readList(b)
+			}
+		}
+		off += 8 + n + n&1
+	}
+	return nil
+}
+
+// readList reads the list b (starting with its type) and its sub lists
+func (avi *aviFile) readList(b []byte) {
+	if len(b) < 4 {
+		return
+	}
+	listType := string(b[:4])
+	if listType == "INFO" {
+		avi.software = riffInfo(b, &avi.tags)
+		return
+	}
+	if listType == "odml" {
+		avi.openDML = true
+	}
+	for b = b[4:]; len(b) >= 8; {
+		// sizes are compared before the int conversion, negative on 32-bit platforms
+		id, size := string(b[:4]), binary.LittleEndian.Uint32(b[4:])
+		if int64(size) > int64(len(b)-8) {
+			return
+		}
+		n := int(size)
+		data := b[8 : 8+n]
+		switch id {
+		case "LIST":
+			if n >= 4 {
+				if string(data[:4]) == "strl" {
+					avi.streams = append(avi.streams, aviStream{})
+				}
+				avi.readList(data)
+			}
+		case "avih":
+			if n >= 40 {
+				avi.totalFrames = binary.LittleEndian.Uint32(data[16:])
+				avi.width = binary.LittleEndian.Uint32(data[32:])
+				avi.height = binary.LittleEndian.Uint32(data[36:])
+			}
+		case "dmlh":
+			if n >= 4 {
+				avi.totalFrames = binary.LittleEndian.Uint32(data)
+			}
+		case "indx":
+			// OpenDML index
+			avi.openDML = true
+		case "strh", "strf", "strn":
+			if len(avi.streams) > 0 {
+				avi.streams[len(avi.streams)-1].readHeader(id, data)
+			}
+		}
+		n += n & 1
+		if 8+n > len(b) {
+			return
+		}
+		b = b[8+n:]
+	}
+}
+
+// readHeader reads the strh, strf or strn chunk data of s
+func (s *aviStream) readHeader(id string, data []byte) {
+	switch id {
+	case "strh":
+		if len(data) >= 36 {
+			s.kind = string(data[:4])
+			s.handler = fourCC(data[4:8])
+			s.scale = binary.LittleEndian.Uint32(data[20:])
+			s.rate = binary.LittleEndian.Uint32(data[24:])
+			s.length = binary.LittleEndian.Uint32(data[32:])
+		}
+	case "strf":
+		s.format = data
+	case "strn":
+		s.name = string(bytes.TrimRight(data, "\x00"))
+	}
+}
+
+// fourCC returns b as a FourCC code, without the padding spaces and NULs
+func fourCC(b []byte) string {
+	return strings.TrimRight(string(b), " \x00")
+}
+
+// info returns the Info of the parsed headers
+func (avi *aviFile) info(size int64) Info {
+	info := Info{General: General{
+		Format:             "AVI",
+		FileSize:           uint(size),
+		EncodedApplication: avi.software,
+		Title:              avi.tags.TrackName,
+		Tags:               avi.tags,
+	}}
+	if avi.openDML {
+		info.General.FormatProfile = "OpenDML"
+	}
+
+	var duration float64
+	for i, s := range avi.streams {
+		d := s.duration()
+		switch s.kind {
+		case "vids", "iavs":
+			v := Video{
+				StreamOrder: uint(i),
+				ID:          uint(i),
+				CodecID:     s.handler,
+				Width:       uint(avi.width),
+				Height:      uint(avi.height),
+				FrameCount:  uint(s.length),
+				Title:       s.name,
+			}
+			if s.kind == "iavs" {
+				v.Format = "DV"
+			}
+			if s.kind == "vids" && len(s.format) >= 20 {
+				// BITMAPINFOHEADER, the strf of iavs is a DVINFO
+				v.Width = uint(binary.LittleEndian.Uint32(s.format[4:]))
+				height := int32(binary.LittleEndian.Uint32(s.format[8:]))
+				if height < 0 {
+					height = -height
+				}
+				v.Height = uint(height)
+				v.CodecID = fourCC(s.format[16:20])
+			}
+			if v.Format == "" {
+				v.Format = aviVideoFormats[strings.ToUpper(v.CodecID)]
+			}
+			if s.scale > 0 {
+				v.FrameRate = float32(float64(s.rate) / float64(s.scale))
+			}
+			if len(info.VideoTracks) == 0 && uint(avi.totalFrames) > v.FrameCount {
+				// the first stream header of OpenDML files only counts the first RIFF
+				v.FrameCount = uint(avi.totalFrames)
+				if v.FrameRate > 0 {
+					d = float64(v.FrameCount) / float64(v.FrameRate)
+				}
+			}
+			v.Duration = float32(d)
+			if len(info.VideoTracks) == 0 {
+				info.General.FrameRate = v.FrameRate
+				info.General.FrameCount = v.FrameCount
+			}
+			info.VideoTracks = append(info.VideoTracks, v)
+		case "auds":
+			var a Audio
+			if len(s.format) >= 16 {
+				a, _, _ = waveFormatAudio(s.format)
+			}
+			a.StreamOrder, a.ID, a.Title = uint(i), uint(i), s.name
+			a.Duration = float32(d)
+			info.AudioTracks = append(info.AudioTracks, a)
+		case "txts":
+			info.TextTracks = append(info.TextTracks, Text{
+				StreamOrder: uint(i),
+				ID:          uint(i),
+				CodecID:     s.handler,
+				Duration:    float32(d),
+			})
+		default:
+			continue
+		}
+		if d > duration {
+			duration = d
+		}
+	}
+
+	info.General.VideoCount = uint(len(info.VideoTracks))
+	info.General.AudioCount = uint(len(info.AudioTracks))
+	info.General.TextCount = uint(len(info.TextTracks))
+	info.General.Duration = float32(duration)
+	if duration > 0 {
+		info.General.OverallBitRate = float32(float64(size) * 8 / duration)
+	}
+	return info
+}
+
+// checkInterleave reports whether the first chunks of the movi list are badly interleaved:
+// a run of consecutive chunks of one stream longer than aviMaxInterleave while the other streams wait.
+func (avi *aviFile) checkInterleave(r io.ReaderAt) (bool, error) {
+	var video, audio int
+	for _, s := range avi.streams {
+		switch s.kind {
+		case "vids", "iavs":
+			video++
+		case "auds":
+			audio++
+		}
+	}
+	if video == 0 || audio == 0 || avi.moviSize <= 0 {
+		return false, nil
+	}
+
+	b, err := readAt(r, avi.moviOffset, int(min64(avi.moviSize, aviInterleaveScan)))
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+
+	var (
+		run       float64
+		runStream = -1
+	)
+	for len(b) >= 8 {
+		id, n := b[:4], int64(binary.LittleEndian.Uint32(b[4:]))
+		if string(id) == "LIST" {
+			// rec lists group the chunks of an interleave period
+			if len(b) < 12 {
+				break
+			}
+			b = b[12:]
+			continue
+		}
+		stream := aviChunkStream(id)
+		if stream >= 0 && stream < len(avi.streams) {
+			if stream != runStream {
+				runStream, run = stream, 0
+			}
+			run += avi.streams[stream].chunkDuration(n)
+			if run > aviMaxInterleave {
+				return true, nil
+			}
+		}
+		n += n & 1
+		if 8+n > int64(len(b)) {
+			break
+		}
+		b = b[8+n:]
+	}
+	return false, nil
+}
+
+// aviChunkStream returns the stream number of a movi chunk id such as "01wb", -1 if none
+func aviChunkStream(id []byte) int {
+	if id[0] < '0' || id[0] > '9' || id[1] < '0' || id[1] > '9' {
+		return -1
+	}
+	return int(id[0]-'0')*10 + int(id[1]-'0')
+}
+
+// chunkDuration returns the duration in seconds of a movi chunk of n bytes of s
+func (s aviStream) chunkDuration(n int64) float64 {
+	if s.rate == 0 {
+		return 0
+	}
+	if s.kind == "auds" && len(s.format) >= 12 {
+		if byteRate := binary.LittleEndian.Uint32(s.format[8:]); byteRate > 0 {
+			return float64(n) / float64(byteRate)
+		}
+	}
+	// one frame per chunk
+	return float64(s.scale) / float64(s.rate)
+}
@@ -0,0 +1,178 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// riffChunk returns a RIFF chunk, word aligned
+func riffChunk(id string, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+	c := append([]byte(id), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(c[4:], uint32(len(body)))
+	c = append(c, body...)
+	if len(body)%2 == 1 {
+		c = append(c, 0)
+	}
+	return c
+}
+
+// riffList returns a LIST (or RIFF with id) chunk of type listType
+func riffList(id, listType string, chunks ...[]byte) []byte {
+	return riffChunk(id, append([][]byte{[]byte(listType)}, chunks...)...)
+}
+
+// le32 returns the little endian values
+func le32(values ...uint32) []byte {
+	b := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(b[4*i:], v)
+	}
+	return b
+}
+
+// testAVIHeaders returns the hdrl list of testAVI
+func testAVIHeaders() []byte {
+	avih := le32(40000, 0, 0, 0x10, 100, 0, 2, 0, 640, 480, 0, 0, 0, 0)
+	vidsHeader := append([]byte("vidsxvid"), le32(0, 0, 0, 1, 25, 0, 100, 0, 0, 0)...)
+	vidsFormat := append(le32(40, 640, 480), 1, 0, 24, 0)
+	vidsFormat = append(vidsFormat, "XVID"...)
+	vidsFormat = append(vidsFormat, le32(0, 0, 0, 0, 0)...)
+	audsHeader := append([]byte("auds\x00\x00\x00\x00"), le32(0, 0, 0, 1, 16000, 0, 160000, 0, 0, 0)...)
+	audsFormat := []byte{0x55, 0, 2, 0}
+	audsFormat = append(audsFormat, le32(44100, 16000)...)
+	audsFormat = append(audsFormat, 1, 0, 0, 0)
+
+	return riffList("LIST", "hdrl",
+		riffChunk("avih", avih),
+		riffList("LIST", "strl", riffChunk("strh", vidsHeader), riffChunk("strf", vidsFormat), riffChunk("strn", []byte("Video\x00"))),
+		riffList("LIST", "strl", riffChunk("strh", audsHeader), riffChunk("strf", audsFormat)),
+		riffList("LIST", "odml", riffChunk("dmlh", le32(250))),
+	)
+}
+
+// testAVI returns an OpenDML AVI with XviD 640x480 25 fps (250 frames) video and MP3 audio.
+// The movi list holds one second of video chunks before the first audio chunk if interleaved is false.
+func testAVI(interleaved bool) []byte {
+	hdrl := testAVIHeaders()
+	info := riffList("LIST", "INFO", riffChunk("INAM", []byte("Home movie\x00")), riffChunk("ISFT", []byte("Lavf58.29.100\x00")))
+
+	var chunks [][]byte
+	video := riffChunk("00dc", make([]byte, 101))
+	audio := riffChunk("01wb", make([]byte, 640)) // 40ms
+	for i := 0; i < 30; i++ {
+		chunks = append(chunks, video)
+		if interleaved {
+			chunks = append(chunks, audio)
+		}
+	}
+	if !interleaved {
+		for i := 0; i < 30; i++ {
+			chunks = append(chunks, audio)
+		}
+	}
+	movi := riffList("LIST", "movi", chunks...)
+
+	b := riffList("RIFF", "AVI ", hdrl, info, movi)
+	return append(b, riffList("RIFF", "AVIX", riffList("LIST", "movi", video, audio))...)
+}
+
+func Test_readAVI(t *testing.T) {
+	for _, interleaved := range []bool{true, false} {
+		data := testAVI(interleaved)
+		info, err := readAVI(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("readAVI() error = %v", err)
+		}
+
+		want := Info{
+			General: General{
+				VideoCount:         1,
+				AudioCount:         1,
+				Format:             "AVI",
+				FormatProfile:      "OpenDML",
+				FileSize:           uint(len(data)),
+				Duration:           10,
+				OverallBitRate:     float32(len(data)) * 8 / 10,
+				FrameRate:          25,
+				FrameCount:         250,
+				NotInterleaved:     !interleaved,
+				EncodedApplication: "Lavf58.29.100",
+				Title:              "Home movie",
+				Tags:               Tags{TrackName: "Home movie"},
+			},
+			VideoTracks: []Video{{StreamOrder: 0, ID: 0, Format: "MPEG-4 Visual", CodecID: "XVID", Duration: 10,
+				Width: 640, Height: 480, FrameRate: 25, FrameCount: 250, Title: "Video"}},
+			AudioTracks: []Audio{{StreamOrder: 1, ID: 1, Format: "MPEG Audio", CodecID: "55", Duration: 10,
+				Channels: 2, SamplingRate: 44100, CompressionMode: "Lossy"}},
+		}
+		if !reflect.DeepEqual(info, want) {
+			t.Errorf("readAVI(interleaved=%v)\nGot \n%+v\nWant\n%+v", interleaved, info, want)
+		}
+		if c := info.VideoTracks[0].Codec(); c != VideoCodecMPEG4 {
+			t.Errorf("Codec() = %v, want MPEG-4", c)
+		}
+	}
+}
+
+func Test_readAVI_malformed(t *testing.T) {
+	video := riffChunk("00dc", make([]byte, 101))
+	audio := riffChunk("01wb", make([]byte, 640))
+	tests := []struct {
+		name   string
+		chunks [][]byte
+	}{
+		{"empty INFO list", [][]byte{[]byte("LIST\x00\x00\x00\x00INFO\x00\x00\x00\x00"), testAVIHeaders(),
+			riffList("LIST", "movi", video, audio)}},
+		{"short hdrl list", [][]byte{testAVIHeaders(), []byte("LIST\x02\x00\x00\x00hdrl"),
+			riffList("LIST", "movi", video, audio)}},
+		{"truncated rec list", [][]byte{testAVIHeaders(),
+			riffList("LIST", "movi", video, audio, []byte("LIST\x00\x00\x00\x00"))}},
+		// sizes negative once converted to a 32-bit int
+		{"huge chunk sizes", [][]byte{riffList("LIST", "INFO", []byte("ISFT\xF0\xFF\xFF\xFF")), testAVIHeaders(),
+			riffList("LIST", "odml", []byte("dmlh\xF0\xFF\xFF\xFF")),
+			riffList("LIST", "movi", video, audio, []byte("01wb\xF0\xFF\xFF\xFF"))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := riffList("RIFF", "AVI ", tt.chunks...)
+			info, err := readAVI(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("readAVI() error = %v", err)
+			}
+			if len(info.VideoTracks) != 1 || len(info.AudioTracks) != 1 {
+				t.Errorf("readAVI() = %+v", info)
+			}
+		})
+	}
+}
+
+func TestInformAVI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-avi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	avi := filepath.Join(dir, "movie.AVI")
+	ioutil.WriteFile(avi, testAVI(true), 0644)
+	info, err := InformAVI(avi)
+	if err != nil {
+		t.Fatalf("InformAVI() error = %v", err)
+	}
+	if info.General.CompleteName != avi || info.General.FileExtension != "avi" || len(info.VideoTracks) != 1 {
+		t.Errorf("InformAVI() = %+v", info)
+	}
+
+	wav := filepath.Join(dir, "sound.avi")
+	ioutil.WriteFile(wav, testWAV(), 0644)
+	if _, err := InformAVI(wav); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("InformAVI() error = %v, want ErrUnsupportedFormat", err)
+	}
+}
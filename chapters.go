@@ -0,0 +1,736 @@
+package mediainfo
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChapterFormat represents a text format used to exchange chapters (Menu entries)
+type ChapterFormat int
+
+const (
+	// ChapterOGM is the OGM simple chapter format (CHAPTER01=00:00:00.000 / CHAPTER01NAME=...)
+	ChapterOGM ChapterFormat = iota
+	// ChapterMatroskaXML is the Matroska chapter XML format used by mkvmerge/mkvextract
+	ChapterMatroskaXML
+	// ChapterFFMetadata is the FFmpeg FFMETADATA1 format
+	ChapterFFMetadata
+	// ChapterWebVTT is a WebVTT file with kind="chapters" cues
+	ChapterWebVTT
+	// ChapterPodlove is the Podlove Simple Chapters JSON representation
+	ChapterPodlove
+	// ChapterYouTube is the list of timestamps used in YouTube video descriptions.
+	// Milliseconds are not represented in this format.
+	ChapterYouTube
+)
+
+func (f ChapterFormat) String() string {
+	switch f {
+	case ChapterOGM:
+		return "OGM"
+	case ChapterMatroskaXML:
+		return "Matroska XML"
+	case ChapterFFMetadata:
+		return "FFMETADATA"
+	case ChapterWebVTT:
+		return "WebVTT"
+	case ChapterPodlove:
+		return "Podlove"
+	case ChapterYouTube:
+		return "YouTube"
+	}
+	return "ChapterFormat(" + strconv.Itoa(int(f)) + ")"
+}
+
+// WriteChapters writes the menu entries to w in format f.
+// Formats other than ChapterMatroskaXML only hold the top level entries with their main title.
+func (m Menu) WriteChapters(w io.Writer, f ChapterFormat) error {
+	return WriteEditions(w, []Menu{m}, f)
+}
+
+// WriteEditions writes every menu as a chapter edition to w in format f.
+// Only ChapterMatroskaXML supports more than one edition.
+func WriteEditions(w io.Writer, menus []Menu, f ChapterFormat) error {
+	if f == ChapterMatroskaXML {
+		return writeMatroskaXML(w, menus)
+	}
+	if len(menus) != 1 {
+		return fmt.Errorf("%s: %w", f, ErrEditionsNotSupported)
+	}
+
+	m := menus[0]
+	switch f {
+	case ChapterOGM:
+		return writeOGM(w, m)
+	case ChapterFFMetadata:
+		return writeFFMetadata(w, m)
+	case ChapterWebVTT:
+		return writeWebVTT(w, m)
+	case ChapterPodlove:
+		return writePodlove(w, m)
+	case ChapterYouTube:
+		return writeYouTube(w, m)
+	}
+	return ErrUnknownChapterFormat
+}
+
+// ReadChapters reads the first chapter edition in format f from r.
+// Entries without an explicit end time end where the next entry starts.
+func ReadChapters(r io.Reader, f ChapterFormat) (Menu, error) {
+	menus, err := ReadEditions(r, f)
+	if err != nil || len(menus) == 0 {
+		return Menu{}, err
+	}
+	return menus[0], nil
+}
+
+// ReadEditions reads all chapter editions in format f from r.
+// Only ChapterMatroskaXML can hold more than one edition.
+func ReadEditions(r io.Reader, f ChapterFormat) (menus []Menu, err error) {
+	var entries []Entry
+	switch f {
+	case ChapterMatroskaXML:
+		menus, err = readMatroskaXML(r)
+	case ChapterOGM:
+		entries, err = readOGM(r)
+	case ChapterFFMetadata:
+		entries, err = readFFMetadata(r)
+	case ChapterWebVTT:
+		entries, err = readWebVTT(r)
+	case ChapterPodlove:
+		entries, err = readPodlove(r)
+	case ChapterYouTube:
+		entries, err = readYouTube(r)
+	default:
+		return nil, ErrUnknownChapterFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if menus == nil {
+		menus = []Menu{{Entries: entries}}
+	}
+	for i := range menus {
+		m := &menus[i]
+		m.Edition = uint(i)
+		normalizeEntries(m.Entries)
+		if len(m.Entries) > 0 {
+			m.Duration = m.Entries[len(m.Entries)-1].EndTime
+		}
+		setEndTimes(m.Entries, m.Duration)
+	}
+	return menus, nil
+}
+
+// normalizeEntries sorts entries and fills the fields derived from the ones read
+func normalizeEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartTime < entries[j].StartTime
+	})
+	for i := range entries {
+		e := &entries[i]
+		if e.StartTimeStr == "" {
+			e.StartTimeStr = toFormatTimeStr(e.StartTime)
+		}
+		if e.Titles == nil {
+			e.Titles = map[Language]string{Language(e.Language): e.Title}
+		}
+		normalizeEntries(e.Entries)
+	}
+}
+
+// setEndTimes sets the end time of every entry without one to the start time
+// of the next entry. The last entry ends at duration.
+// Nested entries end at most where their parent ends.
+func setEndTimes(entries []Entry, duration float32) {
+	for i := range entries {
+		if entries[i].EndTime != 0 {
+			continue
+		}
+		if i < len(entries)-1 {
+			entries[i].EndTime = entries[i+1].StartTime
+		} else {
+			entries[i].EndTime = duration
+		}
+	}
+	for i := range entries {
+		end := entries[i].EndTime
+		entries[i].EndTimeStr = toFormatTimeStr(end)
+		for j := range entries[i].Entries {
+			if child := &entries[i].Entries[j]; end > 0 && child.EndTime > end {
+				child.EndTime = end
+			}
+		}
+		setEndTimes(entries[i].Entries, end)
+	}
+}
+
+// toMilliseconds rounds seconds s to the nearest millisecond
+func toMilliseconds(s float32) int64 {
+	return int64(math.Round(float64(s) * 1000))
+}
+
+func fromMilliseconds(ms int64) float32 {
+	return float32(float64(ms) / 1000)
+}
+
+// parseNPT parses a Normal Play Time, a timestamp or a number of seconds SS[.fff]
+func parseNPT(s string) (float32, error) {
+	if strings.Contains(s, ":") {
+		return parseTimestamp(s)
+	}
+	t, err := parseTimestamp("0:" + strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid chapter timestamp: %q", s)
+	}
+	return t, nil
+}
+
+// parseTimestamp parses [HH:]MM:SS[.fff] (any number of fraction digits, "." or ",") into seconds
+func parseTimestamp(s string) (float32, error) {
+	s = strings.TrimSpace(s)
+	hhmmss, frac := s, ""
+	if idx := strings.IndexAny(s, ".,"); idx >= 0 {
+		hhmmss, frac = s[:idx], s[idx+1:]
+	}
+
+	parts := strings.Split(hhmmss, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid chapter timestamp: %q", s)
+	}
+
+	var ms int64
+	for _, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid chapter timestamp: %q", s)
+		}
+		ms = ms*60 + int64(v)
+	}
+	ms *= 1000
+
+	if frac != "" {
+		// only millisecond precision is kept
+		digits := frac
+		if len(digits) > 3 {
+			digits = digits[:3]
+		}
+		v, err := strconv.ParseUint(digits, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid chapter timestamp: %q", s)
+		}
+		for i := len(digits); i < 3; i++ {
+			v *= 10
+		}
+		ms += int64(v)
+	}
+	return fromMilliseconds(ms), nil
+}
+
+// titleLines returns the non blank lines of title, since a blank line ends a WebVTT cue
+// and the other text formats have one line per chapter
+func titleLines(title string) []string {
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(title, "\r\n", "\n"), "\n") {
+		if l = strings.TrimRight(l, "\r"); strings.TrimSpace(l) != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// singleLine returns title on one line
+func singleLine(title string) string {
+	return strings.Join(titleLines(title), " ")
+}
+
+// OGM
+
+var ogmLine = regexp.MustCompile(`^CHAPTER(\d+)(NAME)?=(.*)$`)
+
+func writeOGM(w io.Writer, m Menu) error {
+	bw := bufio.NewWriter(w)
+	for i, e := range m.Entries {
+		fmt.Fprintf(bw, "CHAPTER%02d=%s\n", i+1, toFormatTimeStr(e.StartTime))
+		fmt.Fprintf(bw, "CHAPTER%02dNAME=%s\n", i+1, singleLine(e.Title))
+	}
+	return bw.Flush()
+}
+
+func readOGM(r io.Reader) ([]Entry, error) {
+	byNumber := map[int]*Entry{}
+	var order []int
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		match := ogmLine.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("invalid OGM chapter line: %q", line)
+		}
+
+		n, _ := strconv.Atoi(match[1])
+		e, ok := byNumber[n]
+		if !ok {
+			e = &Entry{}
+			byNumber[n] = e
+			order = append(order, n)
+		}
+
+		if match[2] != "" {
+			e.Title = match[3]
+			continue
+		}
+		st, err := parseTimestamp(match[3])
+		if err != nil {
+			return nil, err
+		}
+		e.StartTime = st
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Ints(order)
+	entries := make([]Entry, 0, len(order))
+	for _, n := range order {
+		entries = append(entries, *byNumber[n])
+	}
+	return entries, nil
+}
+
+// Matroska XML
+
+type mkvChapters struct {
+	XMLName  xml.Name     `xml:"Chapters"`
+	Editions []mkvEdition `xml:"EditionEntry"`
+}
+
+type mkvEdition struct {
+	UID     uint64       `xml:"EditionUID,omitempty"`
+	Hidden  int          `xml:"EditionFlagHidden,omitempty"`
+	Default int          `xml:"EditionFlagDefault,omitempty"`
+	Ordered int          `xml:"EditionFlagOrdered,omitempty"`
+	Atoms   []mkvChapter `xml:"ChapterAtom"`
+}
+
+type mkvChapter struct {
+	UID      uint64       `xml:"ChapterUID,omitempty"`
+	Start    string       `xml:"ChapterTimeStart"`
+	End      string       `xml:"ChapterTimeEnd,omitempty"`
+	Hidden   int          `xml:"ChapterFlagHidden,omitempty"`
+	Displays []mkvDisplay `xml:"ChapterDisplay"`
+	Atoms    []mkvChapter `xml:"ChapterAtom"`
+}
+
+type mkvDisplay struct {
+	String       string `xml:"ChapterString"`
+	Language     string `xml:"ChapterLanguage,omitempty"`
+	LanguageIETF string `xml:"ChapLanguageIETF,omitempty"`
+}
+
+// toMatroskaTime formats seconds as HH:MM:SS.nnnnnnnnn
+func toMatroskaTime(s float32) string {
+	return toFormatTimeStr(s) + "000000"
+}
+
+func toFlag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func toMatroskaChapters(entries []Entry) []mkvChapter {
+	var atoms []mkvChapter
+	for _, e := range entries {
+		c := mkvChapter{
+			Start:  toMatroskaTime(e.StartTime),
+			Hidden: toFlag(e.Hidden),
+			Atoms:  toMatroskaChapters(e.Entries),
+		}
+		if e.EndTime != 0 {
+			c.End = toMatroskaTime(e.EndTime)
+		}
+
+		// main title first, then the remaining ones sorted by language
+		c.Displays = append(c.Displays, mkvDisplay{String: e.Title, LanguageIETF: e.Language})
+		languages := make([]string, 0, len(e.Titles))
+		for l := range e.Titles {
+			if string(l) != e.Language {
+				languages = append(languages, string(l))
+			}
+		}
+		sort.Strings(languages)
+		for _, l := range languages {
+			c.Displays = append(c.Displays, mkvDisplay{String: e.Titles[Language(l)], LanguageIETF: l})
+		}
+
+		atoms = append(atoms, c)
+	}
+	return atoms
+}
+
+func writeMatroskaXML(w io.Writer, menus []Menu) error {
+	doc := mkvChapters{}
+	for _, m := range menus {
+		doc.Editions = append(doc.Editions, mkvEdition{
+			Hidden:  toFlag(m.Hidden),
+			Default: toFlag(m.Default),
+			Ordered: toFlag(m.Ordered),
+			Atoms:   toMatroskaChapters(m.Entries),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE Chapters SYSTEM \"matroskachapters.dtd\">\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func fromMatroskaChapters(atoms []mkvChapter) ([]Entry, error) {
+	var entries []Entry
+	for _, c := range atoms {
+		e := Entry{Hidden: c.Hidden != 0}
+		var err error
+		if e.StartTime, err = parseTimestamp(c.Start); err != nil {
+			return nil, err
+		}
+		if c.End != "" {
+			if e.EndTime, err = parseTimestamp(c.End); err != nil {
+				return nil, err
+			}
+		}
+
+		for i, d := range c.Displays {
+			language := d.LanguageIETF
+			if language == "" {
+				language = d.Language
+			}
+			if i == 0 {
+				e.Title, e.Language = d.String, language
+				e.Titles = make(map[Language]string, len(c.Displays))
+			}
+			if _, ok := e.Titles[Language(language)]; !ok {
+				e.Titles[Language(language)] = d.String
+			}
+		}
+
+		if e.Entries, err = fromMatroskaChapters(c.Atoms); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func readMatroskaXML(r io.Reader) ([]Menu, error) {
+	var doc mkvChapters
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	menus := make([]Menu, 0, len(doc.Editions))
+	for _, edition := range doc.Editions {
+		entries, err := fromMatroskaChapters(edition.Atoms)
+		if err != nil {
+			return nil, err
+		}
+		menus = append(menus, Menu{
+			Hidden:  edition.Hidden != 0,
+			Default: edition.Default != 0,
+			Ordered: edition.Ordered != 0,
+			Entries: entries,
+		})
+	}
+	return menus, nil
+}
+
+// FFMETADATA
+
+var ffmetadataEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, `;`, `\;`, `#`, `\#`, "\n", "\\\n")
+
+func writeFFMetadata(w io.Writer, m Menu) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(";FFMETADATA1\n")
+	for _, e := range m.Entries {
+		bw.WriteString("[CHAPTER]\nTIMEBASE=1/1000\n")
+		fmt.Fprintf(bw, "START=%d\n", toMilliseconds(e.StartTime))
+		fmt.Fprintf(bw, "END=%d\n", toMilliseconds(e.EndTime))
+		fmt.Fprintf(bw, "title=%s\n", ffmetadataEscaper.Replace(e.Title))
+	}
+	return bw.Flush()
+}
+
+// splitFFMetadata splits an unescaped key=value line
+func splitFFMetadata(line string) (key, value string, ok bool) {
+	var b strings.Builder
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			b.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '=' && !ok:
+			key = b.String()
+			b.Reset()
+			ok = true
+		default:
+			b.WriteRune(c)
+		}
+	}
+	value = b.String()
+	return
+}
+
+func readFFMetadata(r io.Reader) ([]Entry, error) {
+	br := bufio.NewReader(r)
+
+	header, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !strings.HasPrefix(header, ";FFMETADATA") {
+		return nil, fmt.Errorf("invalid FFMETADATA header: %q", strings.TrimSpace(header))
+	}
+
+	var entries []Entry
+	var current *Entry
+	var num, den int64 = 1, 1000
+	var start, end int64
+
+	toSeconds := func(v int64) float32 {
+		if v*num*1000%den == 0 {
+			// exact in milliseconds, avoid float rounding
+			return fromMilliseconds(v * num * 1000 / den)
+		}
+		return float32(float64(v*num) / float64(den))
+	}
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.StartTime = toSeconds(start)
+		current.EndTime = toSeconds(end)
+		entries = append(entries, *current)
+		current = nil
+	}
+
+	var line string
+	for {
+		l, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		eof := err == io.EOF
+		l = strings.TrimRight(l, "\r\n")
+
+		// lines ending with an unescaped backslash (an odd number of them) continue on the next line
+		line += l
+		if n := len(l) - len(strings.TrimRight(l, `\`)); n%2 == 1 && !eof {
+			line += "\n"
+			continue
+		}
+
+		switch {
+		case line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "["):
+			flush()
+			if line == "[CHAPTER]" {
+				current = &Entry{}
+				num, den, start, end = 1, 1000, 0, 0
+			}
+		case current != nil:
+			key, value, ok := splitFFMetadata(line)
+			if !ok {
+				return nil, fmt.Errorf("invalid FFMETADATA line: %q", line)
+			}
+			switch strings.ToUpper(key) {
+			case "TIMEBASE":
+				if _, err := fmt.Sscanf(value, "%d/%d", &num, &den); err != nil || den == 0 {
+					return nil, fmt.Errorf("invalid FFMETADATA timebase: %q", value)
+				}
+			case "START":
+				if start, err = strconv.ParseInt(value, 10, 64); err != nil {
+					return nil, fmt.Errorf("invalid FFMETADATA start: %q", value)
+				}
+			case "END":
+				if end, err = strconv.ParseInt(value, 10, 64); err != nil {
+					return nil, fmt.Errorf("invalid FFMETADATA end: %q", value)
+				}
+			case "TITLE":
+				current.Title = value
+			case "LANGUAGE":
+				current.Language = value
+			}
+		}
+		line = ""
+
+		if eof {
+			break
+		}
+	}
+	flush()
+	return entries, nil
+}
+
+// WebVTT
+
+// webVTTEscaper escapes the cue text, ">" so that a title can't hold "-->"
+var webVTTEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func writeWebVTT(w io.Writer, m Menu) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n")
+	for i, e := range m.Entries {
+		title := webVTTEscaper.Replace(strings.Join(titleLines(e.Title), "\n"))
+		fmt.Fprintf(bw, "\n%d\n%s --> %s\n%s\n", i+1, toFormatTimeStr(e.StartTime), toFormatTimeStr(e.EndTime), title)
+	}
+	return bw.Flush()
+}
+
+func readWebVTT(r io.Reader) ([]Entry, error) {
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() || !strings.HasPrefix(strings.TrimPrefix(sc.Text(), "\ufeff"), "WEBVTT") {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid WebVTT header")
+	}
+
+	var entries []Entry
+	var current *Entry
+	var text []string
+	flush := func() {
+		if current != nil {
+			current.Title = html.UnescapeString(strings.Join(text, "\n"))
+			entries = append(entries, *current)
+		}
+		current, text = nil, nil
+	}
+
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case current != nil:
+			text = append(text, line)
+		case strings.Contains(line, "-->"):
+			times := strings.SplitN(line, "-->", 2)
+			endFields := strings.Fields(times[1]) // drop cue settings
+			if len(endFields) == 0 {
+				return nil, fmt.Errorf("invalid WebVTT cue timing: %q", line)
+			}
+			st, err := parseTimestamp(times[0])
+			if err != nil {
+				return nil, err
+			}
+			et, err := parseTimestamp(endFields[0])
+			if err != nil {
+				return nil, err
+			}
+			current = &Entry{StartTime: st, EndTime: et}
+		}
+		// anything else is a cue identifier, NOTE or STYLE block
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return entries, nil
+}
+
+// Podlove Simple Chapters
+
+type podloveChapter struct {
+	Start string `json:"start"`
+	Title string `json:"title"`
+	Href  string `json:"href,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+func writePodlove(w io.Writer, m Menu) error {
+	chapters := make([]podloveChapter, 0, len(m.Entries))
+	for _, e := range m.Entries {
+		chapters = append(chapters, podloveChapter{Start: toFormatTimeStr(e.StartTime), Title: e.Title})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(chapters)
+}
+
+func readPodlove(r io.Reader) ([]Entry, error) {
+	var chapters []podloveChapter
+	if err := json.NewDecoder(r).Decode(&chapters); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(chapters))
+	for _, c := range chapters {
+		st, err := parseNPT(c.Start)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{StartTime: st, Title: c.Title})
+	}
+	return entries, nil
+}
+
+// YouTube
+
+var youtubeLine = regexp.MustCompile(`^\(?((?:\d{1,2}:)?\d{1,2}:\d{2})\)?\s*(?:[-–—:|]\s*)?(.*)$`)
+
+func writeYouTube(w io.Writer, m Menu) error {
+	bw := bufio.NewWriter(w)
+	hours := m.Duration >= 3600
+	for _, e := range m.Entries {
+		s := toMilliseconds(e.StartTime) / 1000
+		title := singleLine(e.Title)
+		if hours || s >= 3600 {
+			fmt.Fprintf(bw, "%d:%02d:%02d %s\n", s/3600, s/60%60, s%60, title)
+		} else {
+			fmt.Fprintf(bw, "%d:%02d %s\n", s/60, s%60, title)
+		}
+	}
+	return bw.Flush()
+}
+
+func readYouTube(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		match := youtubeLine.FindStringSubmatch(strings.TrimSpace(sc.Text()))
+		if match == nil {
+			// descriptions usually mix timestamps with free text
+			continue
+		}
+		st, err := parseTimestamp(match[1])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{StartTime: st, Title: strings.TrimSpace(match[2])})
+	}
+	return entries, sc.Err()
+}
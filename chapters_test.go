@@ -0,0 +1,330 @@
+package mediainfo
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testMenu() Menu {
+	m := Menu{Duration: 4086.355}
+	starts := []float32{0, 107.607, 854.895, 1361.234}
+	titles := []string{"Chapter 01", "Chapter 02: The = sign; #1", "Chapter 03", "Chapter 04"}
+	for i := range starts {
+		m.Entries = append(m.Entries, Entry{
+			StartTime:    starts[i],
+			StartTimeStr: toFormatTimeStr(starts[i]),
+			Language:     "en",
+			Title:        titles[i],
+			Titles:       map[Language]string{"en": titles[i]},
+		})
+	}
+	setEndTimes(m.Entries, m.Duration)
+	return m
+}
+
+func TestChaptersRoundTrip(t *testing.T) {
+	tests := []struct {
+		format ChapterFormat
+		// which Entry fields survive the format
+		keepEnd, keepLanguage bool
+	}{
+		{format: ChapterOGM},
+		{format: ChapterMatroskaXML, keepEnd: true, keepLanguage: true},
+		{format: ChapterFFMetadata, keepEnd: true},
+		{format: ChapterWebVTT, keepEnd: true},
+		{format: ChapterPodlove},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			in := testMenu()
+			buf := bytes.Buffer{}
+			if err := in.WriteChapters(&buf, tt.format); err != nil {
+				t.Fatalf("WriteChapters() error = %v", err)
+			}
+
+			got, err := ReadChapters(&buf, tt.format)
+			if err != nil {
+				t.Fatalf("ReadChapters() error = %v\n%s", err, buf.String())
+			}
+
+			want := testMenu()
+			for i := range want.Entries {
+				if !tt.keepLanguage {
+					e := &want.Entries[i]
+					e.Language = ""
+					e.Titles = map[Language]string{"": e.Title}
+				}
+			}
+			if !tt.keepEnd {
+				want.Duration = 0
+				want.Entries[len(want.Entries)-1].EndTime = 0
+				want.Entries[len(want.Entries)-1].EndTimeStr = toFormatTimeStr(0)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ReadChapters()\nGot \n%+v\nWant\n%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestChaptersRoundTrip_specialTitles(t *testing.T) {
+	titles := []string{"Q&A <live> &amp;", "Line 1\nLine 2", "a --> b", "Part 1\n\nPart 2\r\n", "  \n"}
+	tests := []struct {
+		format ChapterFormat
+		want   []string
+	}{
+		{ChapterOGM, []string{"Q&A <live> &amp;", "Line 1 Line 2", "a --> b", "Part 1 Part 2", ""}},
+		{ChapterWebVTT, []string{"Q&A <live> &amp;", "Line 1\nLine 2", "a --> b", "Part 1\nPart 2", ""}},
+		{ChapterYouTube, []string{"Q&A <live> &amp;", "Line 1 Line 2", "a --> b", "Part 1 Part 2", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			in := Menu{Duration: 100}
+			for i, title := range titles {
+				in.Entries = append(in.Entries, Entry{StartTime: float32(10 * i), Title: title})
+			}
+			testTitlesRoundTrip(t, in, tt.format, tt.want)
+		})
+	}
+
+	// backslashes before the end of line are escaped, not continuations
+	t.Run(ChapterFFMetadata.String(), func(t *testing.T) {
+		titles := []string{`ends with \`, `two \\`, "back\\\nslash", "x=1;#2"}
+		in := Menu{Duration: 100}
+		for i, title := range titles {
+			in.Entries = append(in.Entries, Entry{StartTime: float32(10 * i), Title: title})
+		}
+		testTitlesRoundTrip(t, in, ChapterFFMetadata, titles)
+	})
+}
+
+// testTitlesRoundTrip writes and reads in back in format, checking the titles read
+func testTitlesRoundTrip(t *testing.T, in Menu, format ChapterFormat, want []string) {
+	t.Helper()
+	setEndTimes(in.Entries, in.Duration)
+
+	buf := bytes.Buffer{}
+	if err := in.WriteChapters(&buf, format); err != nil {
+		t.Fatalf("WriteChapters() error = %v", err)
+	}
+	got, err := ReadChapters(&buf, format)
+	if err != nil {
+		t.Fatalf("ReadChapters() error = %v\n%s", err, buf.String())
+	}
+	var gotTitles []string
+	for _, e := range got.Entries {
+		gotTitles = append(gotTitles, e.Title)
+	}
+	if !reflect.DeepEqual(gotTitles, want) {
+		t.Errorf("ReadChapters() titles = %q, want %q\n%s", gotTitles, want, buf.String())
+	}
+}
+
+func Test_setEndTimes(t *testing.T) {
+	entries := []Entry{
+		{StartTime: 0, Entries: []Entry{{StartTime: 0, EndTime: 5}, {StartTime: 5, EndTime: 15}}},
+		{StartTime: 10, Entries: []Entry{{StartTime: 10}, {StartTime: 12}}},
+	}
+	setEndTimes(entries, 20)
+
+	var got [][]float32
+	for _, e := range entries {
+		ends := []float32{e.EndTime}
+		for _, c := range e.Entries {
+			ends = append(ends, c.EndTime)
+		}
+		got = append(got, ends)
+	}
+	// nested entries end at most where their parent ends
+	want := [][]float32{{10, 5, 10}, {20, 12, 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("setEndTimes() end times = %v, want %v", got, want)
+	}
+}
+
+func TestWriteChaptersYouTube(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := testMenu().WriteChapters(&buf, ChapterYouTube); err != nil {
+		t.Fatal(err)
+	}
+	want := "0:00:00 Chapter 01\n0:01:47 Chapter 02: The = sign; #1\n0:14:14 Chapter 03\n0:22:41 Chapter 04\n"
+	if buf.String() != want {
+		t.Errorf("WriteChapters() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestReadChapters(t *testing.T) {
+	tests := []struct {
+		name   string
+		format ChapterFormat
+		in     string
+		want   []Entry
+	}{
+		{
+			name:   "youtube description",
+			format: ChapterYouTube,
+			in:     "Thanks for watching!\n\n00:00 Intro\n1:47 - Setup\n(1:02:03) Outro\n",
+			want: []Entry{
+				{StartTime: 0, StartTimeStr: "00:00:00.000", EndTime: 107, EndTimeStr: "00:01:47.000", Title: "Intro", Titles: map[Language]string{"": "Intro"}},
+				{StartTime: 107, StartTimeStr: "00:01:47.000", EndTime: 3723, EndTimeStr: "01:02:03.000", Title: "Setup", Titles: map[Language]string{"": "Setup"}},
+				{StartTime: 3723, StartTimeStr: "01:02:03.000", EndTimeStr: "00:00:00.000", Title: "Outro", Titles: map[Language]string{"": "Outro"}},
+			},
+		},
+		{
+			name:   "ffmetadata with timebase and escapes",
+			format: ChapterFFMetadata,
+			in:     ";FFMETADATA1\ntitle=global\n\n[CHAPTER]\nTIMEBASE=1/1000000\nSTART=0\nEND=1500000\ntitle=multi\\\nline \\= ok\n",
+			want: []Entry{
+				{StartTime: 0, StartTimeStr: "00:00:00.000", EndTime: 1.5, EndTimeStr: "00:00:01.500", Title: "multi\nline = ok", Titles: map[Language]string{"": "multi\nline = ok"}},
+			},
+		},
+		{
+			name:   "podlove normal play time",
+			format: ChapterPodlove,
+			in:     `[{"start": "0", "title": "Intro"}, {"start": "12.5", "title": "Setup"}, {"start": "1:02:03", "title": "Outro"}]`,
+			want: []Entry{
+				{StartTime: 0, StartTimeStr: "00:00:00.000", EndTime: 12.5, EndTimeStr: "00:00:12.500", Title: "Intro", Titles: map[Language]string{"": "Intro"}},
+				{StartTime: 12.5, StartTimeStr: "00:00:12.500", EndTime: 3723, EndTimeStr: "01:02:03.000", Title: "Setup", Titles: map[Language]string{"": "Setup"}},
+				{StartTime: 3723, StartTimeStr: "01:02:03.000", EndTimeStr: "00:00:00.000", Title: "Outro", Titles: map[Language]string{"": "Outro"}},
+			},
+		},
+		{
+			name:   "webvtt with settings and identifiers",
+			format: ChapterWebVTT,
+			in:     "WEBVTT - chapters\n\nNOTE generated\n\nintro\n00:00.000 --> 00:10.250 align:start\nIntro\n",
+			want: []Entry{
+				{StartTime: 0, StartTimeStr: "00:00:00.000", EndTime: 10.25, EndTimeStr: "00:00:10.250", Title: "Intro", Titles: map[Language]string{"": "Intro"}},
+			},
+		},
+		{
+			name:   "matroska xml nanoseconds and iso 639-2",
+			format: ChapterMatroskaXML,
+			in: `<?xml version="1.0"?><Chapters><EditionEntry><ChapterAtom><ChapterTimeStart>00:00:05.123456789</ChapterTimeStart>` +
+				`<ChapterDisplay><ChapterString>Five</ChapterString><ChapterLanguage>eng</ChapterLanguage></ChapterDisplay></ChapterAtom></EditionEntry></Chapters>`,
+			want: []Entry{
+				{StartTime: 5.123, StartTimeStr: "00:00:05.123", EndTimeStr: "00:00:00.000", Title: "Five", Language: "eng", Titles: map[Language]string{"eng": "Five"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadChapters(strings.NewReader(tt.in), tt.format)
+			if err != nil {
+				t.Fatalf("ReadChapters() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Entries, tt.want) {
+				t.Errorf("ReadChapters()\nGot \n%+v\nWant\n%+v", got.Entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatroskaXMLEditions(t *testing.T) {
+	in := []Menu{{
+		Default: true,
+		Entries: []Entry{{
+			StartTime: 0,
+			Language:  "en",
+			Title:     "Intro: Part 1",
+			Titles:    map[Language]string{"en": "Intro: Part 1", "fr": "Intro : Partie 1"},
+			Entries: []Entry{
+				{StartTime: 0, Title: "Logo", Language: "en", Hidden: true},
+				{StartTime: 5, Title: "Credits", Language: "en"},
+			},
+		}, {
+			StartTime: 60,
+			EndTime:   90,
+			Language:  "en",
+			Title:     "Main",
+		}},
+	}, {
+		Ordered: true,
+		Hidden:  true,
+		Entries: []Entry{{StartTime: 0, EndTime: 10, Language: "en", Title: "Extra"}},
+	}}
+
+	buf := bytes.Buffer{}
+	if err := WriteEditions(&buf, in, ChapterMatroskaXML); err != nil {
+		t.Fatalf("WriteEditions() error = %v", err)
+	}
+	got, err := ReadEditions(&buf, ChapterMatroskaXML)
+	if err != nil {
+		t.Fatalf("ReadEditions() error = %v", err)
+	}
+
+	if len(got) != 2 || !got[0].Default || got[0].Ordered || !got[1].Ordered || !got[1].Hidden || got[1].Edition != 1 {
+		t.Fatalf("ReadEditions() editions = %+v", got)
+	}
+	intro := got[0].Entries[0]
+	if !reflect.DeepEqual(intro.Titles, in[0].Entries[0].Titles) {
+		t.Errorf("ReadEditions() titles = %v, want %v", intro.Titles, in[0].Entries[0].Titles)
+	}
+	if len(intro.Entries) != 2 || !intro.Entries[0].Hidden || intro.Entries[0].EndTime != 5 || intro.Entries[1].EndTime != 60 {
+		t.Errorf("ReadEditions() nested entries = %+v", intro.Entries)
+	}
+	if got[0].Duration != 90 {
+		t.Errorf("ReadEditions() duration = %v, want 90", got[0].Duration)
+	}
+
+	if err := WriteEditions(&buf, in, ChapterOGM); !errors.Is(err, ErrEditionsNotSupported) {
+		t.Errorf("WriteEditions() error = %v, want %v", err, ErrEditionsNotSupported)
+	}
+}
+
+func Test_parseChapterTitles(t *testing.T) {
+	tests := []struct {
+		arg          string
+		wantLanguage string
+		wantTitle    string
+		wantTitles   map[Language]string
+	}{
+		{arg: "Chapter 01", wantLanguage: "de", wantTitle: "Chapter 01", wantTitles: map[Language]string{"de": "Chapter 01"}},
+		{arg: "en:Chapter 01", wantLanguage: "en", wantTitle: "Chapter 01", wantTitles: map[Language]string{"en": "Chapter 01"}},
+		{arg: "en:Part 1: The Beginning", wantLanguage: "en", wantTitle: "Part 1: The Beginning", wantTitles: map[Language]string{"en": "Part 1: The Beginning"}},
+		{arg: "Part 1: The Beginning", wantLanguage: "de", wantTitle: "Part 1: The Beginning", wantTitles: map[Language]string{"de": "Part 1: The Beginning"}},
+		{arg: "en:Intro - fr:Intro", wantLanguage: "en", wantTitle: "Intro", wantTitles: map[Language]string{"en": "Intro", "fr": "Intro"}},
+		{arg: "en-US:A - B - pt-BR:C: D", wantLanguage: "en-US", wantTitle: "A - B", wantTitles: map[Language]string{"en-US": "A - B", "pt-BR": "C: D"}},
+		{arg: "abc:def", wantLanguage: "de", wantTitle: "abc:def", wantTitles: map[Language]string{"de": "abc:def"}},
+		{arg: "ok: go", wantLanguage: "de", wantTitle: "ok: go", wantTitles: map[Language]string{"de": "ok: go"}},
+		{arg: "en:Act 1 - xyz: begins", wantLanguage: "en", wantTitle: "Act 1 - xyz: begins", wantTitles: map[Language]string{"en": "Act 1 - xyz: begins"}},
+		{arg: "chi:Intro - eng:Intro", wantLanguage: "chi", wantTitle: "Intro", wantTitles: map[Language]string{"chi": "Intro", "eng": "Intro"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			language, title, titles := parseChapterTitles(tt.arg, "de")
+			if language != tt.wantLanguage || title != tt.wantTitle || !reflect.DeepEqual(titles, tt.wantTitles) {
+				t.Errorf("parseChapterTitles() = %q, %q, %v, want %q, %q, %v", language, title, titles, tt.wantLanguage, tt.wantTitle, tt.wantTitles)
+			}
+		})
+	}
+}
+
+func Test_parseTimestamp(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    float32
+		wantErr bool
+	}{
+		{arg: "00:00:00.000", want: 0},
+		{arg: "01:25:30.675", want: 5130.675},
+		{arg: "25:30", want: 1530},
+		{arg: "00:00:01,5", want: 1.5},
+		{arg: "00:00:01.000000001", want: 1},
+		{arg: "1", wantErr: true},
+		{arg: "aa:bb", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := parseTimestamp(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTimestamp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
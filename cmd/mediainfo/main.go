@@ -0,0 +1,103 @@
+// Command mediainfo prints and checks media information using github.com/prcoito/mediainfo.
+//
+// Usage:
+//
+//	mediainfo inform [-lib path] [-text] file|-...
+//	mediainfo validate [-lib path] -spec spec.yaml [-json] file|folder...
+//	mediainfo serve [-lib path] [-addr :8080] [-root dir]... [-max-upload bytes] [-concurrency n] [-timeout d]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/prcoito/mediainfo"
+	"github.com/prcoito/mediainfo/format"
+)
+
+// commands are the subcommands, each returns the process exit code
+var commands = map[string]func(args []string) int{
+	"inform":   inform,
+	"validate": validate,
+	"serve":    serve,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprintln(os.Stderr, "usage: mediainfo <inform|validate|serve> [flags] path...")
+		os.Exit(2)
+	}
+	os.Exit(commands[os.Args[1]](os.Args[2:]))
+}
+
+// load loads libmediainfo from lib or the default locations
+func load(lib string) error {
+	if lib != "" {
+		return mediainfo.LoadFrom(lib)
+	}
+	if !mediainfo.Load() {
+		return mediainfo.ErrLibraryNotFound
+	}
+	return nil
+}
+
+func inform(args []string) int {
+	fs := flag.NewFlagSet("inform", flag.ExitOnError)
+	lib := fs.String("lib", "", "path of libmediainfo, default locations if empty")
+	text := fs.Bool("text", false, "print a text report like the mediainfo command line instead of JSON")
+	fs.Parse(args)
+
+	if err := load(*lib); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer mediainfo.Unload()
+
+	code := 0
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	for _, f := range fs.Args() {
+		var info mediainfo.Info
+		var err error
+		if f == "-" {
+			info, err = informStdin()
+		} else {
+			info, err = mediainfo.Inform(f)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+		if *text {
+			format.Report(os.Stdout, info)
+			continue
+		}
+		enc.Encode(info)
+	}
+	return code
+}
+
+// informStdin analyzes the standard input, reading it until libmediainfo needs no more data
+func informStdin() (mediainfo.Info, error) {
+	a, err := mediainfo.NewAnalyzer(-1)
+	if err != nil {
+		return mediainfo.Info{}, err
+	}
+	buf := make([]byte, 64<<10)
+	for !a.State().Done {
+		n, err := os.Stdin.Read(buf)
+		a.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			a.Close()
+			return mediainfo.Info{}, err
+		}
+	}
+	return a.Finalize()
+}
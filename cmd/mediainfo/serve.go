@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prcoito/mediainfo"
+	"github.com/prcoito/mediainfo/server"
+)
+
+// stringsFlag is a flag which can be repeated
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func serve(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	lib := fs.String("lib", "", "path of libmediainfo, default locations if empty")
+	addr := fs.String("addr", ":8080", "listen address")
+	var roots stringsFlag
+	fs.Var(&roots, "root", "directory allowed for GET /inform?path=, can be repeated")
+	maxUpload := fs.Int64("max-upload", server.DefaultMaxUploadSize, "maximum upload size in bytes")
+	concurrency := fs.Int("concurrency", 0, "maximum concurrent analyses, number of CPUs if 0")
+	timeout := fs.Duration("timeout", server.DefaultTimeout, "maximum duration of a request")
+	fs.Parse(args)
+
+	if err := load(*lib); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer mediainfo.Unload()
+
+	s, err := server.New(server.Config{
+		Roots:         roots,
+		MaxUploadSize: *maxUpload,
+		MaxConcurrent: *concurrency,
+		Timeout:       *timeout,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		// uploads are read during the analysis, bounded by the server timeout
+		WriteTimeout: *timeout + 10*time.Second,
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	// done is closed once the running requests are over, shutdownErr is set before
+	done := make(chan struct{})
+	var shutdownErr error
+	go func() {
+		defer close(done)
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		shutdownErr = srv.Shutdown(ctx)
+	}()
+
+	log.Printf("listening on %s", *addr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	<-done
+	if shutdownErr != nil {
+		fmt.Fprintln(os.Stderr, shutdownErr)
+		return 1
+	}
+	return 0
+}
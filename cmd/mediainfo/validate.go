@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/prcoito/mediainfo"
+	"github.com/prcoito/mediainfo/spec"
+)
+
+// fileReport is the result of validating a file
+type fileReport struct {
+	Path       string
+	Error      string `json:",omitempty"`
+	Violations []spec.Violation
+}
+
+func validate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	lib := fs.String("lib", "", "path of libmediainfo, default locations if empty")
+	specPath := fs.String("spec", "", "spec file (.yaml, .yml or .json)")
+	asJSON := fs.Bool("json", false, "print the reports as JSON")
+	fs.Parse(args)
+
+	if *specPath == "" || fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: mediainfo validate -spec spec.yaml file|folder...")
+		return 2
+	}
+
+	s, err := spec.Load(*specPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if err := load(*lib); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer mediainfo.Unload()
+
+	var reports []fileReport
+	for _, root := range fs.Args() {
+		err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				reports = append(reports, fileReport{Path: path, Error: err.Error()})
+				return nil
+			}
+			if fi.IsDir() {
+				return nil
+			}
+
+			info, err := mediainfo.Inform(path)
+			if errors.Is(err, mediainfo.ErrUnsupportedFormat) && path != root {
+				// not a media file in a folder
+				return nil
+			}
+			if err != nil {
+				reports = append(reports, fileReport{Path: path, Error: err.Error()})
+				return nil
+			}
+			reports = append(reports, fileReport{Path: path, Violations: s.Validate(info)})
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	code := 0
+	for _, r := range reports {
+		if r.Error != "" || spec.HasErrors(r.Violations) {
+			code = 1
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(reports)
+		return code
+	}
+	for _, r := range reports {
+		switch {
+		case r.Error != "":
+			fmt.Printf("%s: %s\n", r.Path, r.Error)
+		case len(r.Violations) == 0:
+			fmt.Printf("%s: OK\n", r.Path)
+		default:
+			for _, v := range r.Violations {
+				fmt.Printf("%s: %s\n", r.Path, v)
+			}
+		}
+	}
+	return code
+}
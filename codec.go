@@ -0,0 +1,563 @@
+package mediainfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VideoCodec represents a video codec independently of the container
+type VideoCodec int
+
+// Video codecs
+const (
+	VideoCodecUnknown VideoCodec = iota
+	VideoCodecH264
+	VideoCodecHEVC
+	VideoCodecVVC
+	VideoCodecAV1
+	VideoCodecVP8
+	VideoCodecVP9
+	VideoCodecMPEG1
+	VideoCodecMPEG2
+	VideoCodecMPEG4 // MPEG-4 Part 2 (DivX, Xvid)
+	VideoCodecVC1
+	VideoCodecProRes
+	VideoCodecDNxHD
+	VideoCodecFFV1
+	VideoCodecTheora
+	VideoCodecMJPEG
+)
+
+var videoCodecNames = map[VideoCodec]string{
+	VideoCodecUnknown: "Unknown",
+	VideoCodecH264:    "H.264",
+	VideoCodecHEVC:    "HEVC",
+	VideoCodecVVC:     "VVC",
+	VideoCodecAV1:     "AV1",
+	VideoCodecVP8:     "VP8",
+	VideoCodecVP9:     "VP9",
+	VideoCodecMPEG1:   "MPEG-1",
+	VideoCodecMPEG2:   "MPEG-2",
+	VideoCodecMPEG4:   "MPEG-4 Visual",
+	VideoCodecVC1:     "VC-1",
+	VideoCodecProRes:  "ProRes",
+	VideoCodecDNxHD:   "DNxHD",
+	VideoCodecFFV1:    "FFV1",
+	VideoCodecTheora:  "Theora",
+	VideoCodecMJPEG:   "MJPEG",
+}
+
+func (c VideoCodec) String() string {
+	if s, ok := videoCodecNames[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("VideoCodec(%d)", int(c))
+}
+
+// AudioCodec represents an audio codec independently of the container
+type AudioCodec int
+
+// Audio codecs
+const (
+	AudioCodecUnknown AudioCodec = iota
+	AudioCodecAAC                // AAC with a profile other than LC, HE-AAC and HE-AACv2
+	AudioCodecAACLC
+	AudioCodecHEAAC
+	AudioCodecHEAACv2
+	AudioCodecMP2
+	AudioCodecMP3
+	AudioCodecAC3
+	AudioCodecEAC3
+	AudioCodecTrueHD
+	AudioCodecDTS
+	AudioCodecDTSHD // DTS-HD Master Audio
+	AudioCodecFLAC
+	AudioCodecALAC
+	AudioCodecOpus
+	AudioCodecVorbis
+	AudioCodecPCM
+	AudioCodecWMA
+)
+
+var audioCodecNames = map[AudioCodec]string{
+	AudioCodecUnknown: "Unknown",
+	AudioCodecAAC:     "AAC",
+	AudioCodecAACLC:   "AAC-LC",
+	AudioCodecHEAAC:   "HE-AAC",
+	AudioCodecHEAACv2: "HE-AACv2",
+	AudioCodecMP2:     "MP2",
+	AudioCodecMP3:     "MP3",
+	AudioCodecAC3:     "AC-3",
+	AudioCodecEAC3:    "E-AC-3",
+	AudioCodecTrueHD:  "TrueHD",
+	AudioCodecDTS:     "DTS",
+	AudioCodecDTSHD:   "DTS-HD MA",
+	AudioCodecFLAC:    "FLAC",
+	AudioCodecALAC:    "ALAC",
+	AudioCodecOpus:    "Opus",
+	AudioCodecVorbis:  "Vorbis",
+	AudioCodecPCM:     "PCM",
+	AudioCodecWMA:     "WMA",
+}
+
+func (c AudioCodec) String() string {
+	if s, ok := audioCodecNames[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("AudioCodec(%d)", int(c))
+}
+
+// SubtitleCodec represents a subtitle format independently of the container
+type SubtitleCodec int
+
+// Subtitle codecs
+const (
+	SubtitleCodecUnknown SubtitleCodec = iota
+	SubtitleCodecSRT
+	SubtitleCodecSSA
+	SubtitleCodecASS
+	SubtitleCodecWebVTT
+	SubtitleCodecTTML
+	SubtitleCodecTX3G // MP4 timed text (mov_text)
+	SubtitleCodecPGS
+	SubtitleCodecVobSub
+	SubtitleCodecDVB
+	SubtitleCodecCEA608
+	SubtitleCodecCEA708
+)
+
+var subtitleCodecNames = map[SubtitleCodec]string{
+	SubtitleCodecUnknown: "Unknown",
+	SubtitleCodecSRT:     "SRT",
+	SubtitleCodecSSA:     "SSA",
+	SubtitleCodecASS:     "ASS",
+	SubtitleCodecWebVTT:  "WebVTT",
+	SubtitleCodecTTML:    "TTML",
+	SubtitleCodecTX3G:    "TX3G",
+	SubtitleCodecPGS:     "PGS",
+	SubtitleCodecVobSub:  "VobSub",
+	SubtitleCodecDVB:     "DVB",
+	SubtitleCodecCEA608:  "CEA-608",
+	SubtitleCodecCEA708:  "CEA-708",
+}
+
+func (c SubtitleCodec) String() string {
+	if s, ok := subtitleCodecNames[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("SubtitleCodec(%d)", int(c))
+}
+
+// videoFormats maps libmediainfo video formats to codecs
+var videoFormats = map[string]VideoCodec{
+	"AVC":           VideoCodecH264,
+	"HEVC":          VideoCodecHEVC,
+	"VVC":           VideoCodecVVC,
+	"AV1":           VideoCodecAV1,
+	"VP8":           VideoCodecVP8,
+	"VP9":           VideoCodecVP9,
+	"MPEG-4 Visual": VideoCodecMPEG4,
+	"VC-1":          VideoCodecVC1,
+	"ProRes":        VideoCodecProRes,
+	"VC-3":          VideoCodecDNxHD,
+	"FFV1":          VideoCodecFFV1,
+	"Theora":        VideoCodecTheora,
+	"JPEG":          VideoCodecMJPEG,
+}
+
+// videoCodecIDs maps container codec IDs (Matroska, MP4 sample entries, AVI FourCC) to codecs
+var videoCodecIDs = map[string]VideoCodec{
+	"V_MPEG4/ISO/AVC":  VideoCodecH264,
+	"avc1":             VideoCodecH264,
+	"avc3":             VideoCodecH264,
+	"H264":             VideoCodecH264,
+	"V_MPEGH/ISO/HEVC": VideoCodecHEVC,
+	"hvc1":             VideoCodecHEVC,
+	"hev1":             VideoCodecHEVC,
+	"vvc1":             VideoCodecVVC,
+	"vvi1":             VideoCodecVVC,
+	"V_AV1":            VideoCodecAV1,
+	"av01":             VideoCodecAV1,
+	"V_VP8":            VideoCodecVP8,
+	"vp08":             VideoCodecVP8,
+	"V_VP9":            VideoCodecVP9,
+	"vp09":             VideoCodecVP9,
+	"V_MPEG1":          VideoCodecMPEG1,
+	"V_MPEG2":          VideoCodecMPEG2,
+	"mp2v":             VideoCodecMPEG2,
+	"V_MPEG4/ISO/ASP":  VideoCodecMPEG4,
+	"V_MPEG4/ISO/SP":   VideoCodecMPEG4,
+	"mp4v":             VideoCodecMPEG4,
+	"XVID":             VideoCodecMPEG4,
+	"DIVX":             VideoCodecMPEG4,
+	"DX50":             VideoCodecMPEG4,
+	"WVC1":             VideoCodecVC1,
+	"V_PRORES":         VideoCodecProRes,
+	"apch":             VideoCodecProRes,
+	"apcn":             VideoCodecProRes,
+	"apcs":             VideoCodecProRes,
+	"apco":             VideoCodecProRes,
+	"ap4h":             VideoCodecProRes,
+	"AVdn":             VideoCodecDNxHD,
+	"FFV1":             VideoCodecFFV1,
+	"V_THEORA":         VideoCodecTheora,
+	"V_MJPEG":          VideoCodecMJPEG,
+	"MJPG":             VideoCodecMJPEG,
+}
+
+// NormalizeVideoCodec returns the codec of a video track from its libmediainfo Format,
+// CodecID and FormatProfile. The codec ID is used when the format is unknown.
+func NormalizeVideoCodec(format, codecID, profile string) VideoCodec {
+	if format == "MPEG Video" {
+		// MPEG-1 has no profile
+		if codecID == "V_MPEG1" || (profile == "" && codecID != "V_MPEG2" && codecID != "mp2v" && codecID != "MPG2") {
+			return VideoCodecMPEG1
+		}
+		return VideoCodecMPEG2
+	}
+	if c, ok := videoFormats[format]; ok {
+		return c
+	}
+	return videoCodecIDs[codecID]
+}
+
+// audioCodecIDs maps container codec IDs to codecs
+var audioCodecIDs = map[string]AudioCodec{
+	"mp4a-40-2":          AudioCodecAACLC,
+	"mp4a-40-5":          AudioCodecHEAAC,
+	"mp4a-40-29":         AudioCodecHEAACv2,
+	"mp4a-40-34":         AudioCodecMP3,
+	"mp4a-6B":            AudioCodecMP3,
+	"mp4a-69":            AudioCodecMP3,
+	"A_AAC/MPEG4/LC":     AudioCodecAACLC,
+	"A_AAC/MPEG2/LC":     AudioCodecAACLC,
+	"A_AAC-2":            AudioCodecAACLC,
+	"A_AAC/MPEG4/LC/SBR": AudioCodecHEAAC,
+	"A_AAC-5":            AudioCodecHEAAC,
+	"A_MPEG/L2":          AudioCodecMP2,
+	"A_MPEG/L3":          AudioCodecMP3,
+	"55":                 AudioCodecMP3,
+	"A_AC3":              AudioCodecAC3,
+	"ac-3":               AudioCodecAC3,
+	"A_EAC3":             AudioCodecEAC3,
+	"ec-3":               AudioCodecEAC3,
+	"A_TRUEHD":           AudioCodecTrueHD,
+	"mlpa":               AudioCodecTrueHD,
+	"A_DTS":              AudioCodecDTS,
+	"dtsc":               AudioCodecDTS,
+	"dtsh":               AudioCodecDTSHD,
+	"dtsl":               AudioCodecDTSHD,
+	"A_FLAC":             AudioCodecFLAC,
+	"fLaC":               AudioCodecFLAC,
+	"A_ALAC":             AudioCodecALAC,
+	"alac":               AudioCodecALAC,
+	"A_OPUS":             AudioCodecOpus,
+	"Opus":               AudioCodecOpus,
+	"A_VORBIS":           AudioCodecVorbis,
+	"A_PCM/INT/LIT":      AudioCodecPCM,
+	"A_PCM/INT/BIG":      AudioCodecPCM,
+	"A_PCM/FLOAT/IEEE":   AudioCodecPCM,
+	"lpcm":               AudioCodecPCM,
+	"sowt":               AudioCodecPCM,
+	"twos":               AudioCodecPCM,
+	"1":                  AudioCodecPCM,
+	"161":                AudioCodecWMA,
+}
+
+// NormalizeAudioCodec returns the codec of an audio track from its libmediainfo Format,
+// CodecID and FormatProfile (or Format_AdditionalFeatures for AAC and DTS)
+func NormalizeAudioCodec(format, codecID, profile string) AudioCodec {
+	switch format {
+	case "AAC":
+		return aacCodec(codecID, profile)
+	case "MPEG Audio":
+		switch {
+		case strings.Contains(profile, "Layer 2"), codecID == "A_MPEG/L2":
+			return AudioCodecMP2
+		case strings.Contains(profile, "Layer 3"), codecID == "A_MPEG/L3", codecID == "55":
+			return AudioCodecMP3
+		}
+	case "AC-3":
+		return AudioCodecAC3
+	case "E-AC-3":
+		return AudioCodecEAC3
+	case "MLP FBA", "TrueHD":
+		return AudioCodecTrueHD
+	case "DTS":
+		if strings.Contains(profile, "MA") || strings.Contains(profile, "XLL") {
+			return AudioCodecDTSHD
+		}
+		return AudioCodecDTS
+	case "FLAC":
+		return AudioCodecFLAC
+	case "ALAC":
+		return AudioCodecALAC
+	case "Opus":
+		return AudioCodecOpus
+	case "Vorbis":
+		return AudioCodecVorbis
+	case "PCM":
+		return AudioCodecPCM
+	case "WMA":
+		return AudioCodecWMA
+	}
+	return audioCodecIDs[codecID]
+}
+
+// aacCodec returns the AAC codec from the profile ("LC", "LC SBR", "LC SBR PS", "HE-AACv2 / HE-AAC / LC"...)
+// or from the codec ID
+func aacCodec(codecID, profile string) AudioCodec {
+	// old libmediainfo versions list the compatible profiles, the first one is the actual one
+	p := strings.TrimSpace(strings.Split(profile, "/")[0])
+	switch p {
+	case "LC":
+		return AudioCodecAACLC
+	case "LC SBR", "HE-AAC":
+		return AudioCodecHEAAC
+	case "LC SBR PS", "HE-AACv2":
+		return AudioCodecHEAACv2
+	case "":
+		if c, ok := audioCodecIDs[codecID]; ok {
+			return c
+		}
+	}
+	return AudioCodecAAC
+}
+
+// subtitleFormats maps libmediainfo text formats to codecs
+var subtitleFormats = map[string]SubtitleCodec{
+	"SubRip":       SubtitleCodecSRT,
+	"SSA":          SubtitleCodecSSA,
+	"ASS":          SubtitleCodecASS,
+	"WebVTT":       SubtitleCodecWebVTT,
+	"TTML":         SubtitleCodecTTML,
+	"Timed Text":   SubtitleCodecTX3G,
+	"PGS":          SubtitleCodecPGS,
+	"VobSub":       SubtitleCodecVobSub,
+	"RLE":          SubtitleCodecVobSub,
+	"DVB Subtitle": SubtitleCodecDVB,
+	"EIA-608":      SubtitleCodecCEA608,
+	"EIA-708":      SubtitleCodecCEA708,
+}
+
+// subtitleCodecIDs maps container codec IDs to codecs
+var subtitleCodecIDs = map[string]SubtitleCodec{
+	"S_TEXT/UTF8":   SubtitleCodecSRT,
+	"S_TEXT/ASCII":  SubtitleCodecSRT,
+	"S_TEXT/SSA":    SubtitleCodecSSA,
+	"S_TEXT/ASS":    SubtitleCodecASS,
+	"S_TEXT/WEBVTT": SubtitleCodecWebVTT,
+	"wvtt":          SubtitleCodecWebVTT,
+	"stpp":          SubtitleCodecTTML,
+	"tx3g":          SubtitleCodecTX3G,
+	"S_HDMV/PGS":    SubtitleCodecPGS,
+	"S_VOBSUB":      SubtitleCodecVobSub,
+	"S_DVBSUB":      SubtitleCodecDVB,
+	"c608":          SubtitleCodecCEA608,
+	"c708":          SubtitleCodecCEA708,
+}
+
+// NormalizeSubtitleCodec returns the codec of a text track from its libmediainfo Format and CodecID.
+// Matroska text subtitles have the character encoding (e.g. "UTF-8") as format, the codec ID is used then.
+func NormalizeSubtitleCodec(format, codecID string) SubtitleCodec {
+	if c, ok := subtitleCodecIDs[codecID]; ok {
+		return c
+	}
+	return subtitleFormats[format]
+}
+
+// Codec returns the codec of v
+func (v Video) Codec() VideoCodec {
+	return NormalizeVideoCodec(v.Format, v.CodecID, v.FormatProfile)
+}
+
+// Codec returns the codec of a
+func (a Audio) Codec() AudioCodec {
+	profile := a.FormatProfile
+	if a.Format == "AAC" || a.Format == "DTS" {
+		profile = a.FormatAdditionalFeatures
+		if profile == "" {
+			profile = a.FormatProfile
+		}
+	}
+	return NormalizeAudioCodec(a.Format, a.CodecID, profile)
+}
+
+// Codec returns the codec of t
+func (t Text) Codec() SubtitleCodec {
+	return NormalizeSubtitleCodec(t.Format, t.CodecID)
+}
+
+// h264Profiles maps H.264 profiles to profile_idc and constraint flags
+var h264Profiles = map[string][2]byte{
+	"Constrained Baseline":  {0x42, 0xE0},
+	"Baseline":              {0x42, 0x00},
+	"Main":                  {0x4D, 0x40},
+	"Extended":              {0x58, 0x00},
+	"High":                  {0x64, 0x00},
+	"High 10":               {0x6E, 0x00},
+	"High 4:2:2":            {0x7A, 0x00},
+	"High 4:4:4 Predictive": {0xF4, 0x00},
+}
+
+// hevcProfiles maps HEVC profiles to general_profile_idc and general_profile_compatibility_flags
+// (bit reversed, as written in codecs strings)
+var hevcProfiles = map[string][2]int{
+	"Main":          {1, 0x6},
+	"Main 10":       {2, 0x4},
+	"Main Still":    {3, 0x8},
+	"Format Range":  {4, 0x10},
+	"Main 4:2:2 10": {4, 0x10},
+	"Main 4:4:4":    {4, 0x10},
+	"Main 4:4:4 10": {4, 0x10},
+}
+
+var av1Profiles = map[string]int{"Main": 0, "High": 1, "Professional": 2}
+
+// CodecString returns the RFC 6381 codecs parameter of v, e.g. "avc1.640028" or "hvc1.2.4.L153.B0"
+func (v Video) CodecString() (string, error) {
+	profile, level := splitProfileLevel(v.FormatProfile, v.FormatLevel)
+
+	switch v.Codec() {
+	case VideoCodecH264:
+		p, ok := h264Profiles[profile]
+		l, err := levelNumber(level, 10)
+		if !ok || err != nil {
+			break
+		}
+		entry := "avc1"
+		if v.CodecID == "avc3" {
+			entry = "avc3"
+		}
+		return fmt.Sprintf("%s.%02X%02X%02X", entry, p[0], p[1], l), nil
+	case VideoCodecHEVC:
+		p, ok := hevcProfiles[profile]
+		l, err := levelNumber(level, 30)
+		if !ok || err != nil {
+			break
+		}
+		entry := "hvc1"
+		if v.CodecID == "hev1" {
+			entry = "hev1"
+		}
+		tier := "L"
+		if v.FormatTier == "High" {
+			tier = "H"
+		}
+		return fmt.Sprintf("%s.%d.%X.%s%d.B0", entry, p[0], p[1], tier, l), nil
+	case VideoCodecAV1:
+		p, ok := av1Profiles[profile]
+		l, err := av1Level(level)
+		if !ok || err != nil {
+			break
+		}
+		tier := "M"
+		if v.FormatTier == "High" {
+			tier = "H"
+		}
+		depth := v.BitDepth
+		if depth == 0 {
+			depth = 8
+		}
+		return fmt.Sprintf("av01.%d.%02d%s.%02d", p, l, tier, depth), nil
+	case VideoCodecVP9:
+		p, errP := strconv.Atoi(profile)
+		l, errL := levelNumber(level, 10)
+		if errP != nil || errL != nil {
+			return "vp9", nil
+		}
+		depth := v.BitDepth
+		if depth == 0 {
+			depth = 8
+		}
+		return fmt.Sprintf("vp09.%02d.%02d.%02d", p, l, depth), nil
+	case VideoCodecVP8:
+		return "vp8", nil
+	case VideoCodecMPEG2:
+		return "mp4v.61", nil
+	case VideoCodecMPEG4:
+		return "mp4v.20", nil
+	}
+	return "", fmt.Errorf("%w: %s %s", ErrNoCodecString, v.Codec(), v.FormatProfile)
+}
+
+// audioCodecStrings are the RFC 6381 codecs parameters of audio codecs
+var audioCodecStrings = map[AudioCodec]string{
+	AudioCodecAACLC:   "mp4a.40.2",
+	AudioCodecHEAAC:   "mp4a.40.5",
+	AudioCodecHEAACv2: "mp4a.40.29",
+	AudioCodecMP2:     "mp4a.40.33",
+	AudioCodecMP3:     "mp4a.40.34",
+	AudioCodecAC3:     "ac-3",
+	AudioCodecEAC3:    "ec-3",
+	AudioCodecTrueHD:  "mlpa",
+	AudioCodecDTS:     "dtsc",
+	AudioCodecDTSHD:   "dtsl",
+	AudioCodecFLAC:    "fLaC",
+	AudioCodecALAC:    "alac",
+	AudioCodecOpus:    "Opus",
+	AudioCodecVorbis:  "vorbis",
+}
+
+// CodecString returns the RFC 6381 codecs parameter of a, e.g. "mp4a.40.2" or "ec-3"
+func (a Audio) CodecString() (string, error) {
+	if s, ok := audioCodecStrings[a.Codec()]; ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNoCodecString, a.Codec())
+}
+
+// subtitleCodecStrings are the RFC 6381 codecs parameters of subtitle codecs
+var subtitleCodecStrings = map[SubtitleCodec]string{
+	SubtitleCodecWebVTT: "wvtt",
+	SubtitleCodecTTML:   "stpp.ttml.im1t",
+	SubtitleCodecTX3G:   "tx3g",
+}
+
+// CodecString returns the RFC 6381 codecs parameter of t, e.g. "wvtt"
+func (t Text) CodecString() (string, error) {
+	if s, ok := subtitleCodecStrings[t.Codec()]; ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNoCodecString, t.Codec())
+}
+
+// splitProfileLevel returns the profile and level, old libmediainfo versions report them together, e.g. "High@L4.1"
+func splitProfileLevel(profile, level string) (string, string) {
+	if i := strings.Index(profile, "@L"); i >= 0 {
+		if level == "" {
+			level = profile[i+2:]
+			if j := strings.Index(level, "@"); j >= 0 {
+				level = level[:j]
+			}
+		}
+		profile = profile[:i]
+	}
+	return profile, level
+}
+
+// levelNumber returns level (e.g. "4.1") multiplied by factor, as written in codecs strings
+func levelNumber(level string, factor float64) (int, error) {
+	f, err := strconv.ParseFloat(level, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(f*factor + 0.5), nil
+}
+
+// av1Level returns the seq_level_idx of an AV1 level, e.g. 13 for "5.1"
+func av1Level(level string) (int, error) {
+	parts := strings.SplitN(level, ".", 2)
+	major, err := strconv.Atoi(parts[0])
+	if err != nil || major < 2 {
+		return 0, fmt.Errorf("invalid AV1 level %q", level)
+	}
+	minor := 0
+	if len(parts) == 2 {
+		if minor, err = strconv.Atoi(parts[1]); err != nil {
+			return 0, err
+		}
+	}
+	return (major-2)*4 + minor, nil
+}
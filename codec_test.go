@@ -0,0 +1,101 @@
+package mediainfo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVideo_Codec(t *testing.T) {
+	tests := []struct {
+		name       string
+		video      Video
+		want       VideoCodec
+		wantString string
+	}{
+		{name: "mkv avc", video: Video{Format: "AVC", CodecID: "V_MPEG4/ISO/AVC", FormatProfile: "High", FormatLevel: "4"}, want: VideoCodecH264, wantString: "avc1.640028"},
+		{name: "old profile@level", video: Video{Format: "AVC", CodecID: "avc1", FormatProfile: "Main@L3.1"}, want: VideoCodecH264, wantString: "avc1.4D401F"},
+		{name: "hev1", video: Video{Format: "HEVC", CodecID: "hev1", FormatProfile: "Main 10", FormatLevel: "5.1", FormatTier: "Main"}, want: VideoCodecHEVC, wantString: "hev1.2.4.L153.B0"},
+		{name: "hvc1 high tier", video: Video{Format: "HEVC", CodecID: "V_MPEGH/ISO/HEVC", FormatProfile: "Main", FormatLevel: "4.1", FormatTier: "High"}, want: VideoCodecHEVC, wantString: "hvc1.1.6.H123.B0"},
+		{name: "av1", video: Video{Format: "AV1", CodecID: "V_AV1", FormatProfile: "Main", FormatLevel: "5.1", BitDepth: 10}, want: VideoCodecAV1, wantString: "av01.0.13M.10"},
+		{name: "vp9", video: Video{Format: "VP9", CodecID: "V_VP9", FormatProfile: "0", FormatLevel: "3.1"}, want: VideoCodecVP9, wantString: "vp09.00.31.08"},
+		{name: "codec ID only", video: Video{CodecID: "hvc1"}, want: VideoCodecHEVC},
+		{name: "mpeg-2", video: Video{Format: "MPEG Video", FormatProfile: "Main", FormatLevel: "Main"}, want: VideoCodecMPEG2, wantString: "mp4v.61"},
+		{name: "prores", video: Video{Format: "ProRes", CodecID: "apch"}, want: VideoCodecProRes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.video.Codec(); got != tt.want {
+				t.Errorf("Codec() = %v, want %v", got, tt.want)
+			}
+			got, err := tt.video.CodecString()
+			if tt.wantString == "" {
+				if !errors.Is(err, ErrNoCodecString) {
+					t.Errorf("CodecString() error = %v, want ErrNoCodecString", err)
+				}
+				return
+			}
+			if err != nil || got != tt.wantString {
+				t.Errorf("CodecString() = %q, %v, want %q", got, err, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestAudio_Codec(t *testing.T) {
+	tests := []struct {
+		name       string
+		audio      Audio
+		want       AudioCodec
+		wantString string
+	}{
+		{name: "mkv aac lc", audio: Audio{Format: "AAC", FormatAdditionalFeatures: "LC", CodecID: "A_AAC-2"}, want: AudioCodecAACLC, wantString: "mp4a.40.2"},
+		{name: "he-aacv2", audio: Audio{Format: "AAC", FormatAdditionalFeatures: "LC SBR PS", CodecID: "mp4a-40-2"}, want: AudioCodecHEAACv2, wantString: "mp4a.40.29"},
+		{name: "old he-aac profile", audio: Audio{Format: "AAC", FormatProfile: "HE-AAC / LC", CodecID: "mp4a-40-5"}, want: AudioCodecHEAAC, wantString: "mp4a.40.5"},
+		{name: "aac codec ID only", audio: Audio{Format: "AAC", CodecID: "mp4a-40-2"}, want: AudioCodecAACLC, wantString: "mp4a.40.2"},
+		{name: "mp3", audio: Audio{Format: "MPEG Audio", FormatProfile: "Layer 3", CodecID: "A_MPEG/L3"}, want: AudioCodecMP3, wantString: "mp4a.40.34"},
+		{name: "e-ac-3", audio: Audio{Format: "E-AC-3", CodecID: "A_EAC3"}, want: AudioCodecEAC3, wantString: "ec-3"},
+		{name: "truehd", audio: Audio{Format: "MLP FBA", CodecID: "A_TRUEHD"}, want: AudioCodecTrueHD, wantString: "mlpa"},
+		{name: "dts-hd ma", audio: Audio{Format: "DTS", FormatAdditionalFeatures: "XLL", CodecID: "A_DTS"}, want: AudioCodecDTSHD, wantString: "dtsl"},
+		{name: "codec ID only", audio: Audio{CodecID: "A_OPUS"}, want: AudioCodecOpus, wantString: "Opus"},
+		{name: "pcm", audio: Audio{Format: "PCM", CodecID: "A_PCM/INT/LIT"}, want: AudioCodecPCM},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.audio.Codec(); got != tt.want {
+				t.Errorf("Codec() = %v, want %v", got, tt.want)
+			}
+			got, err := tt.audio.CodecString()
+			if tt.wantString == "" {
+				if !errors.Is(err, ErrNoCodecString) {
+					t.Errorf("CodecString() error = %v, want ErrNoCodecString", err)
+				}
+				return
+			}
+			if err != nil || got != tt.wantString {
+				t.Errorf("CodecString() = %q, %v, want %q", got, err, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestText_Codec(t *testing.T) {
+	tests := []struct {
+		text Text
+		want SubtitleCodec
+	}{
+		{Text{Format: "UTF-8", CodecID: "S_TEXT/UTF8"}, SubtitleCodecSRT},
+		{Text{Format: "ASS", CodecID: "S_TEXT/ASS"}, SubtitleCodecASS},
+		{Text{Format: "PGS", CodecID: "S_HDMV/PGS"}, SubtitleCodecPGS},
+		{Text{Format: "Timed Text", CodecID: "tx3g"}, SubtitleCodecTX3G},
+		{Text{Format: "WebVTT"}, SubtitleCodecWebVTT},
+		{Text{Format: "EIA-608"}, SubtitleCodecCEA608},
+	}
+	for _, tt := range tests {
+		if got := tt.text.Codec(); got != tt.want {
+			t.Errorf("%+v.Codec() = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+	if s, err := (Text{Format: "WebVTT"}).CodecString(); s != "wvtt" || err != nil {
+		t.Errorf("CodecString() = %q, %v, want wvtt", s, err)
+	}
+}
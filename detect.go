@@ -0,0 +1,243 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Container represents a file format, detected from its first bytes by Detect
+type Container int
+
+// Containers
+const (
+	ContainerUnknown Container = iota
+	ContainerMatroska
+	ContainerWebM
+	ContainerMP4
+	ContainerQuickTime
+	ContainerMPEGTS
+	ContainerBDAV // MPEG-TS with 4 bytes timestamps before each packet (Blu-ray, AVCHD)
+	ContainerMPEGPS
+	ContainerAVI
+	ContainerASF
+	ContainerFLV
+	ContainerMXF
+	ContainerWAV
+	ContainerFLAC
+	ContainerOgg
+	ContainerMP3
+	ContainerADTS
+	ContainerJPEG
+	ContainerPNG
+	ContainerGIF
+	ContainerBMP
+	ContainerTIFF
+	ContainerWebP
+)
+
+// containerInfo is the libmediainfo General.Format, MIME type and file extensions of a container
+type containerInfo struct {
+	format     string
+	mimeType   string
+	extensions []string
+}
+
+var containers = map[Container]containerInfo{
+	ContainerUnknown:   {"Unknown", "application/octet-stream", nil},
+	ContainerMatroska:  {"Matroska", "video/x-matroska", []string{"mkv", "mka", "mks", "mk3d"}},
+	ContainerWebM:      {"WebM", "video/webm", []string{"webm", "weba"}},
+	ContainerMP4:       {"MPEG-4", "video/mp4", []string{"mp4", "m4v", "m4a", "m4b", "3gp", "3g2"}},
+	ContainerQuickTime: {"MPEG-4", "video/quicktime", []string{"mov", "qt"}},
+	ContainerMPEGTS:    {"MPEG-TS", "video/mp2t", []string{"ts", "trp", "tp", "m2t"}},
+	ContainerBDAV:      {"BDAV", "video/mp2t", []string{"m2ts", "mts"}},
+	ContainerMPEGPS:    {"MPEG-PS", "video/mpeg", []string{"mpg", "mpeg", "vob", "m2p"}},
+	ContainerAVI:       {"AVI", "video/x-msvideo", []string{"avi", "divx"}},
+	ContainerASF:       {"Windows Media", "video/x-ms-asf", []string{"asf", "wmv", "wma"}},
+	ContainerFLV:       {"Flash Video", "video/x-flv", []string{"flv"}},
+	ContainerMXF:       {"MXF", "application/mxf", []string{"mxf"}},
+	ContainerWAV:       {"Wave", "audio/wav", []string{"wav", "bwf", "rf64"}},
+	ContainerFLAC:      {"FLAC", "audio/flac", []string{"flac"}},
+	ContainerOgg:       {"Ogg", "audio/ogg", []string{"ogg", "oga", "ogv", "opus", "spx", "ogx"}},
+	ContainerMP3:       {"MPEG Audio", "audio/mpeg", []string{"mp3", "mp2", "mpa"}},
+	ContainerADTS:      {"ADTS", "audio/aac", []string{"aac", "adts"}},
+	ContainerJPEG:      {"JPEG", "image/jpeg", []string{"jpg", "jpeg", "jpe"}},
+	ContainerPNG:       {"PNG", "image/png", []string{"png"}},
+	ContainerGIF:       {"GIF", "image/gif", []string{"gif"}},
+	ContainerBMP:       {"Bitmap", "image/bmp", []string{"bmp"}},
+	ContainerTIFF:      {"TIFF", "image/tiff", []string{"tif", "tiff"}},
+	ContainerWebP:      {"WebP", "image/webp", []string{"webp"}},
+}
+
+// String returns the container name as in libmediainfo General.Format, e.g. "Matroska" or "MPEG-4".
+// MP4 and QuickTime files are both "MPEG-4".
+func (c Container) String() string {
+	if i, ok := containers[c]; ok {
+		return i.format
+	}
+	return fmt.Sprintf("Container(%d)", int(c))
+}
+
+// MIMEType returns the MIME type of the container, e.g. "video/x-matroska"
+func (c Container) MIMEType() string {
+	if i, ok := containers[c]; ok {
+		return i.mimeType
+	}
+	return containers[ContainerUnknown].mimeType
+}
+
+// Extensions returns the usual file extensions of the container, lower case without the dot
+func (c Container) Extensions() []string {
+	return containers[c].extensions
+}
+
+// MatchesExtension reports whether ext (e.g. "mkv", ".MKV" or General.FileExtension) is a usual
+// extension of the container. A false result for a known container means the file is misnamed.
+func (c Container) MatchesExtension(ext string) bool {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	for _, e := range containers[c].extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// detectSize is the number of bytes read by Detect
+const detectSize = 8 << 10
+
+// Detect returns the container of the data read from r from its magic bytes, reading at most
+// the first 8 KiB. No analysis is done, use Inform for the tracks.
+// ContainerUnknown and ErrUnsupportedFormat are returned if no format is recognized.
+func Detect(r io.Reader) (Container, error) {
+	b := make([]byte, detectSize)
+	n, err := io.ReadFull(r, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ContainerUnknown, err
+	}
+	if c := detect(b[:n]); c != ContainerUnknown {
+		return c, nil
+	}
+	return ContainerUnknown, ErrUnsupportedFormat
+}
+
+// asfHeaderGUID is the GUID of the ASF header object
+var asfHeaderGUID = []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11, 0xA6, 0xD9, 0x00, 0xAA, 0x00, 0x62, 0xCE, 0x6C}
+
+// detect returns the container of the first bytes b of a file
+func detect(b []byte) Container {
+	has := func(off int, magic string) bool {
+		return len(b) >= off+len(magic) && string(b[off:off+len(magic)]) == magic
+	}
+	switch {
+	case has(0, "\x1A\x45\xDF\xA3"):
+		return detectEBML(b)
+	case has(4, "ftyp"):
+		if has(8, "qt  ") {
+			return ContainerQuickTime
+		}
+		return ContainerMP4
+	case has(4, "moov"), has(4, "mdat"), has(4, "wide"), has(4, "free"), has(4, "skip"):
+		// QuickTime files may have no ftyp box
+		return ContainerQuickTime
+	case has(0, "RIFF") && has(8, "AVI "):
+		return ContainerAVI
+	case (has(0, "RIFF") || has(0, "RF64")) && has(8, "WAVE"):
+		return ContainerWAV
+	case has(0, "RIFF") && has(8, "WEBP"):
+		return ContainerWebP
+	case bytes.HasPrefix(b, asfHeaderGUID):
+		return ContainerASF
+	case has(0, "FLV\x01"):
+		return ContainerFLV
+	case has(0, "\x06\x0E\x2B\x34"):
+		// SMPTE universal label of the header partition pack
+		return ContainerMXF
+	case has(0, "\x00\x00\x01\xBA"):
+		return ContainerMPEGPS
+	case has(0, "OggS"):
+		return ContainerOgg
+	case has(0, "fLaC"):
+		return ContainerFLAC
+	case has(0, "\xFF\xD8\xFF"):
+		return ContainerJPEG
+	case has(0, "\x89PNG\r\n\x1A\n"):
+		return ContainerPNG
+	case has(0, "GIF87a"), has(0, "GIF89a"):
+		return ContainerGIF
+	case has(0, "II*\x00"), has(0, "MM\x00*"):
+		return ContainerTIFF
+	case has(0, "BM") && len(b) >= 14 && binary.LittleEndian.Uint32(b[6:]) == 0:
+		// reserved fields are zero
+		return ContainerBMP
+	}
+	if c := detectTransportStream(b); c != ContainerUnknown {
+		return c
+	}
+	return detectAudio(b)
+}
+
+// detectEBML returns ContainerWebM or ContainerMatroska from the EBML header DocType
+func detectEBML(b []byte) Container {
+	if i := bytes.Index(b, []byte{0x42, 0x82}); i >= 0 && i+3 <= len(b) {
+		// DocType element ID, 1 byte size, value
+		if bytes.HasPrefix(b[i+3:], []byte("webm")) {
+			return ContainerWebM
+		}
+	}
+	return ContainerMatroska
+}
+
+// detectTransportStream returns ContainerMPEGTS or ContainerBDAV if b starts with 3 packets
+func detectTransportStream(b []byte) Container {
+	sync := func(off, packetSize int) bool {
+		for i := 0; i < 3; i++ {
+			p := off + i*packetSize
+			if p >= len(b) || b[p] != tsSyncByte {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case sync(0, tsPacketSize):
+		return ContainerMPEGTS
+	case sync(4, m2tsPacketSize):
+		return ContainerBDAV
+	}
+	return ContainerUnknown
+}
+
+// detectAudio returns the container of raw audio streams, optionally after an ID3v2 tag
+func detectAudio(b []byte) Container {
+	if start := id3v2Size(bytes.NewReader(b)); start > 0 {
+		if start >= int64(len(b)) {
+			// a large tag, most likely with a cover, before MPEG audio
+			return ContainerMP3
+		}
+		b = b[start:]
+		if bytes.HasPrefix(b, []byte("fLaC")) {
+			return ContainerFLAC
+		}
+	}
+	// a frame confirmed by the next one, unless the data ends first
+	next := func(size int) []byte {
+		if size >= len(b) {
+			return nil
+		}
+		return b[size:]
+	}
+	if h, ok := parseADTSHeader(b); ok {
+		if _, ok := parseADTSHeader(next(h.size)); ok || h.size >= len(b) {
+			return ContainerADTS
+		}
+	}
+	if h, ok := parseMPEGAudioHeader(b); ok {
+		if _, ok := parseMPEGAudioHeader(next(h.size)); ok || h.size >= len(b) {
+			return ContainerMP3
+		}
+	}
+	return ContainerUnknown
+}
@@ -0,0 +1,106 @@
+package mediainfo
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// ebmlHeader returns an EBML header with docType
+func ebmlHeader(docType string) []byte {
+	b := []byte{0x1A, 0x45, 0xDF, 0xA3, 0x80 | byte(4+len(docType)), 0x42, 0x82, 0x80 | byte(len(docType))}
+	b = append(b, docType...)
+	return append(b, 0x42, 0x87, 0x81, 0x04)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want Container
+	}{
+		{"Matroska", ebmlHeader("matroska"), ContainerMatroska},
+		{"WebM", ebmlHeader("webm"), ContainerWebM},
+		{"MP4", []byte("\x00\x00\x00\x18ftypisom\x00\x00\x02\x00isomiso2"), ContainerMP4},
+		{"QuickTime", []byte("\x00\x00\x00\x14ftypqt  \x00\x00\x02\x00qt  "), ContainerQuickTime},
+		{"QuickTime without ftyp", []byte("\x00\x00\x00\x08wide\x00\x10\x00\x00mdat"), ContainerQuickTime},
+		{"MPEG-TS", testTransportStream(false), ContainerMPEGTS},
+		{"BDAV", testTransportStream(true), ContainerBDAV},
+		{"MPEG-PS", []byte("\x00\x00\x01\xBA\x44\x00\x04\x00\x04\x01"), ContainerMPEGPS},
+		{"AVI", testAVI(true), ContainerAVI},
+		{"ASF", append(append([]byte{}, asfHeaderGUID...), 0, 1, 0, 0), ContainerASF},
+		{"FLV", []byte("FLV\x01\x05\x00\x00\x00\x09"), ContainerFLV},
+		{"MXF", []byte("\x06\x0E\x2B\x34\x02\x05\x01\x01\x0D\x01\x02\x01\x01\x02\x04\x00"), ContainerMXF},
+		{"WAV", testWAV(), ContainerWAV},
+		{"FLAC", testFLAC(), ContainerFLAC},
+		{"FLAC after ID3", append(testID3v2(), testFLAC()...), ContainerFLAC},
+		{"Ogg", testOpus(), ContainerOgg},
+		{"MP3", testMP3(10), ContainerMP3},
+		{"MP3 with a large ID3 tag", append([]byte("ID3\x04\x00\x00\x00\x10\x00\x00"), make([]byte, detectSize)...), ContainerMP3},
+		{"ADTS", testADTS(3), ContainerADTS},
+		{"JPEG", []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF"), ContainerJPEG},
+		{"PNG", []byte("\x89PNG\r\n\x1A\n\x00\x00\x00\x0DIHDR"), ContainerPNG},
+		{"GIF", []byte("GIF89a\x01\x00\x01\x00"), ContainerGIF},
+		{"BMP", []byte("BM\x3E\x00\x00\x00\x00\x00\x00\x00\x3E\x00\x00\x00"), ContainerBMP},
+		{"TIFF", []byte("II*\x00\x08\x00\x00\x00"), ContainerTIFF},
+		{"WebP", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), ContainerWebP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, data := range [][]byte{nil, []byte("plain text, not media"), append([]byte{0xFF, 0xFB, 0x90, 0x00}, make([]byte, 600)...)} {
+		if got, err := Detect(bytes.NewReader(data)); got != ContainerUnknown || err != ErrUnsupportedFormat {
+			t.Errorf("Detect(%q) = %v, %v, want ErrUnsupportedFormat", data, got, err)
+		}
+	}
+}
+
+func TestDetect_File(t *testing.T) {
+	f, err := os.Open("testdata/1_video_1_audio_1_menu.mkv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c, err := Detect(f)
+	if err != nil || c != ContainerMatroska {
+		t.Fatalf("Detect() = %v, %v, want Matroska", c, err)
+	}
+	if c.String() != "Matroska" || c.MIMEType() != "video/x-matroska" || !c.MatchesExtension("mkv") {
+		t.Errorf("Container %v: MIME %s, extensions %v", c, c.MIMEType(), c.Extensions())
+	}
+}
+
+func TestContainer_MatchesExtension(t *testing.T) {
+	tests := []struct {
+		c    Container
+		ext  string
+		want bool
+	}{
+		{ContainerMatroska, "mkv", true},
+		{ContainerMatroska, ".MKA", true},
+		{ContainerMatroska, "mp4", false},
+		{ContainerQuickTime, "mov", true},
+		{ContainerMP4, "mov", false},
+		{ContainerBDAV, "m2ts", true},
+		{ContainerMPEGTS, "m2ts", false},
+		{ContainerMP3, "wav", false},
+		{ContainerUnknown, "", false},
+	}
+	for _, tt := range tests {
+		if got := tt.c.MatchesExtension(tt.ext); got != tt.want {
+			t.Errorf("%v.MatchesExtension(%q) = %v, want %v", tt.c, tt.ext, got, tt.want)
+		}
+	}
+	if s := Container(99).String(); s != "Container(99)" {
+		t.Errorf("String() = %s", s)
+	}
+}
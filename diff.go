@@ -0,0 +1,245 @@
+package mediainfo
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// ChangeKind represents the kind of a Change
+type ChangeKind int
+
+const (
+	// Modified means a field value changed
+	Modified ChangeKind = iota
+	// Added means a track (or menu entry, tag...) is only present in the second Info
+	Added
+	// Removed means a track (or menu entry, tag...) is only present in the first Info
+	Removed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Modified:
+		return "modified"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change represents a difference between two Info.
+// Path uses the Go field names and the track index in the first Info
+// (in the second one for added tracks), e.g. "AudioTracks[1].BitRate".
+type Change struct {
+	Kind ChangeKind
+	Path string
+	Old  interface{} // nil if added
+	New  interface{} // nil if removed
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s", c.Path)
+	case Removed:
+		return fmt.Sprintf("- %s", c.Path)
+	}
+	return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// DiffOptions configures Diff
+type DiffOptions struct {
+	// Tolerances are the maximum absolute differences of float fields by field name (e.g. "Duration")
+	// below which values are considered equal
+	Tolerances map[string]float64
+	// IgnoreFileFields ignores fields specific to the file location and times:
+	// CompleteName, FileCreatedDate and FileModifiedDate
+	IgnoreFileFields bool
+	// Ignore are field names (e.g. "EncodedDate") or paths (e.g. "General.Title") not compared
+	Ignore []string
+}
+
+// DefaultDiffOptions returns the options used to compare a file with its remux:
+// small Duration, FrameRate and BitRate differences and file fields are ignored.
+func DefaultDiffOptions() DiffOptions {
+	return DiffOptions{
+		Tolerances: map[string]float64{
+			"Duration":       0.05,
+			"FrameRate":      0.001,
+			"BitRate":        1000,
+			"OverallBitRate": 1000,
+		},
+		IgnoreFileFields: true,
+	}
+}
+
+// fileFields are the fields ignored with DiffOptions.IgnoreFileFields
+var fileFields = []string{"CompleteName", "FileCreatedDate", "FileModifiedDate"}
+
+// Diff returns the changes from a to b.
+// Tracks are matched by UniqueID, then by StreamOrder; menus by edition.
+func Diff(a, b Info, opts DiffOptions) []Change {
+	d := &differ{opts: opts, ignore: map[string]bool{}}
+	for _, f := range opts.Ignore {
+		d.ignore[f] = true
+	}
+	if opts.IgnoreFileFields {
+		for _, f := range fileFields {
+			d.ignore[f] = true
+		}
+	}
+
+	d.compare("General", "", reflect.ValueOf(a.General), reflect.ValueOf(b.General))
+	d.compareTracks("VideoTracks", reflect.ValueOf(a.VideoTracks), reflect.ValueOf(b.VideoTracks))
+	d.compareTracks("AudioTracks", reflect.ValueOf(a.AudioTracks), reflect.ValueOf(b.AudioTracks))
+	d.compareTracks("TextTracks", reflect.ValueOf(a.TextTracks), reflect.ValueOf(b.TextTracks))
+	d.compareTracks("MenuTracks", reflect.ValueOf(a.MenuTracks), reflect.ValueOf(b.MenuTracks))
+	d.compare("Programs", "", reflect.ValueOf(a.Programs), reflect.ValueOf(b.Programs))
+	return d.changes
+}
+
+type differ struct {
+	opts    DiffOptions
+	ignore  map[string]bool
+	changes []Change
+}
+
+func (d *differ) add(kind ChangeKind, path string, old, new interface{}) {
+	if d.ignore[path] {
+		return
+	}
+	d.changes = append(d.changes, Change{Kind: kind, Path: path, Old: old, New: new})
+}
+
+// trackKey returns the keys used to match a track: UniqueID and StreamOrder (Edition for menus)
+func trackKey(v reflect.Value) (uniqueID string, order interface{}) {
+	if f := v.FieldByName("UniqueID"); f.IsValid() {
+		uniqueID = f.String()
+	}
+	if f := v.FieldByName("StreamOrder"); f.IsValid() {
+		order = f.Interface()
+	} else if f := v.FieldByName("Edition"); f.IsValid() {
+		order = f.Interface()
+	}
+	return
+}
+
+func (d *differ) compareTracks(name string, a, b reflect.Value) {
+	if d.ignore[name] {
+		return
+	}
+
+	matched := make([]bool, b.Len())
+	pairs := make([]int, a.Len())
+
+	// first by UniqueID, then by StreamOrder
+	for pass := 0; pass < 2; pass++ {
+		for i := 0; i < a.Len(); i++ {
+			if pass == 0 {
+				pairs[i] = -1
+			} else if pairs[i] >= 0 {
+				continue
+			}
+			idA, orderA := trackKey(a.Index(i))
+			for j := 0; j < b.Len(); j++ {
+				if matched[j] {
+					continue
+				}
+				idB, orderB := trackKey(b.Index(j))
+				if (pass == 0 && idA != "" && idA == idB) || (pass == 1 && orderA == orderB) {
+					pairs[i], matched[j] = j, true
+					break
+				}
+			}
+		}
+	}
+
+	for i := 0; i < a.Len(); i++ {
+		path := fmt.Sprintf("%s[%d]", name, i)
+		if pairs[i] < 0 {
+			d.add(Removed, path, a.Index(i).Interface(), nil)
+			continue
+		}
+		d.compare(path, "", a.Index(i), b.Index(pairs[i]))
+	}
+	for j := 0; j < b.Len(); j++ {
+		if !matched[j] {
+			d.add(Added, fmt.Sprintf("%s[%d]", name, j), nil, b.Index(j).Interface())
+		}
+	}
+}
+
+// compare compares a and b at path, field is the name of the struct field holding them
+func (d *differ) compare(path, field string, a, b reflect.Value) {
+	if d.ignore[path] || (field != "" && d.ignore[field]) {
+		return
+	}
+
+	if t, ok := a.Interface().(time.Time); ok {
+		if !t.Equal(b.Interface().(time.Time)) {
+			d.add(Modified, path, a.Interface(), b.Interface())
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			sf := a.Type().Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			d.compare(path+"."+sf.Name, sf.Name, a.Field(i), b.Field(i))
+		}
+	case reflect.Slice:
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= b.Len():
+				d.add(Removed, p, a.Index(i).Interface(), nil)
+			case i >= a.Len():
+				d.add(Added, p, nil, b.Index(i).Interface())
+			default:
+				d.compare(p, field, a.Index(i), b.Index(i))
+			}
+		}
+	case reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, k := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for n := range keys {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			p := path + "." + n
+			if n == "" {
+				p = path + `[""]`
+			}
+			va, vb := a.MapIndex(keys[n]), b.MapIndex(keys[n])
+			switch {
+			case !vb.IsValid():
+				d.add(Removed, p, va.Interface(), nil)
+			case !va.IsValid():
+				d.add(Added, p, nil, vb.Interface())
+			default:
+				d.compare(p, field, va, vb)
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		if math.Abs(a.Float()-b.Float()) > d.opts.Tolerances[field] {
+			d.add(Modified, path, a.Interface(), b.Interface())
+		}
+	default:
+		if a.Interface() != b.Interface() {
+			d.add(Modified, path, a.Interface(), b.Interface())
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package mediainfo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func diffInfo() Info {
+	return Info{
+		General: General{
+			CompleteName:     "/a/movie.mkv",
+			Format:           "Matroska",
+			Duration:         4086.355,
+			FileModifiedDate: time.Date(2020, 11, 25, 19, 9, 36, 0, time.UTC),
+			Tags:             Tags{Custom: map[string]string{"ISRC": "X"}},
+		},
+		VideoTracks: []Video{{StreamOrder: 0, UniqueID: "1", Format: "HEVC", FrameRate: 23.976, BitRate: 5950000}},
+		AudioTracks: []Audio{
+			{StreamOrder: 1, UniqueID: "2", Format: "AAC", Language: "en"},
+			{StreamOrder: 2, UniqueID: "3", Format: "AC-3", Language: "fr"},
+		},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := diffInfo()
+	b := diffInfo()
+	b.General.CompleteName = "/b/movie.mkv"
+	b.General.Duration = 4086.36
+	b.General.FileModifiedDate = time.Now()
+	b.General.Tags.Custom = map[string]string{"ISRC": "Y", "Encoder": "z"}
+	b.VideoTracks[0].BitRate = 5950400
+	// remuxed: tracks swapped and new UniqueIDs for the audio tracks
+	b.AudioTracks = []Audio{
+		{StreamOrder: 1, UniqueID: "20", Format: "AC-3", Language: "fr"},
+		{StreamOrder: 2, UniqueID: "30", Format: "AAC", Language: "en"},
+	}
+	b.TextTracks = []Text{{StreamOrder: 3, Format: "UTF-8"}}
+
+	got := Diff(a, b, DefaultDiffOptions())
+	want := []Change{
+		{Kind: Added, Path: "General.Tags.Custom.Encoder", New: "z"},
+		{Kind: Modified, Path: "General.Tags.Custom.ISRC", Old: "X", New: "Y"},
+		{Kind: Modified, Path: "AudioTracks[0].UniqueID", Old: "2", New: "20"},
+		{Kind: Modified, Path: "AudioTracks[0].Format", Old: "AAC", New: "AC-3"},
+		{Kind: Modified, Path: "AudioTracks[0].Language", Old: "en", New: "fr"},
+		{Kind: Modified, Path: "AudioTracks[1].UniqueID", Old: "3", New: "30"},
+		{Kind: Modified, Path: "AudioTracks[1].Format", Old: "AC-3", New: "AAC"},
+		{Kind: Modified, Path: "AudioTracks[1].Language", Old: "fr", New: "en"},
+		{Kind: Added, Path: "TextTracks[0]", New: b.TextTracks[0]},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff()\nGot \n%v\nWant\n%v", got, want)
+	}
+
+	got = Diff(a, b, DiffOptions{Ignore: []string{"Tags", "AudioTracks", "TextTracks[0]"}})
+	want = []Change{
+		{Kind: Modified, Path: "General.Duration", Old: float32(4086.355), New: float32(4086.36)},
+		{Kind: Modified, Path: "General.FileModifiedDate", Old: a.General.FileModifiedDate, New: b.General.FileModifiedDate},
+		{Kind: Modified, Path: "General.CompleteName", Old: "/a/movie.mkv", New: "/b/movie.mkv"},
+		{Kind: Modified, Path: "VideoTracks[0].BitRate", Old: float32(5950000), New: float32(5950400)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff()\nGot \n%v\nWant\n%v", got, want)
+	}
+}
+
+func TestDiffMatchByUniqueID(t *testing.T) {
+	a := diffInfo()
+	b := diffInfo()
+	// track order changed, same UniqueIDs
+	b.AudioTracks[0].StreamOrder, b.AudioTracks[1].StreamOrder = 2, 1
+	b.AudioTracks[0], b.AudioTracks[1] = b.AudioTracks[1], b.AudioTracks[0]
+	b.VideoTracks = nil
+
+	got := Diff(a, b, DefaultDiffOptions())
+	want := []Change{
+		{Kind: Removed, Path: "VideoTracks[0]", Old: a.VideoTracks[0]},
+		{Kind: Modified, Path: "AudioTracks[0].StreamOrder", Old: uint(1), New: uint(2)},
+		{Kind: Modified, Path: "AudioTracks[1].StreamOrder", Old: uint(2), New: uint(1)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff()\nGot \n%v\nWant\n%v", got, want)
+	}
+}
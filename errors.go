@@ -1,6 +1,113 @@
 package mediainfo
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
 
 // ErrNotLoaded is the error returned if function Load() was not called before any call
 var ErrNotLoaded = errors.New("Loaded not called previously")
+
+// ErrLibraryNotFound is the error returned when the DLL/shared object can't be loaded
+var ErrLibraryNotFound = errors.New("libmediainfo not found")
+
+// ErrLibraryTooOld is the error returned when the loaded libmediainfo is older than MinimumVersion
+var ErrLibraryTooOld = errors.New("libmediainfo is too old")
+
+// ErrUnsupportedFormat is the error returned when libmediainfo does not recognize the file format
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// ErrUnknownOption is the error returned when setting an option not known by this module
+var ErrUnknownOption = errors.New("unknown option")
+
+// ErrUnknownChapterFormat is the error returned when reading or writing chapters in an unsupported ChapterFormat
+var ErrUnknownChapterFormat = errors.New("unknown chapter format")
+
+// ErrEditionsNotSupported is the error returned when writing more than one chapter edition in a format that cannot hold them
+var ErrEditionsNotSupported = errors.New("chapter format does not support multiple editions")
+
+// ErrNoCodecString is the error returned when a track has no RFC 6381 codecs string,
+// because its codec can't be used in HLS/DASH manifests or its profile or level is unknown
+var ErrNoCodecString = errors.New("no RFC 6381 codecs string")
+
+// ErrRangeNotSupported is the error returned by InformURL when the server does not support HTTP range requests
+var ErrRangeNotSupported = errors.New("HTTP range requests not supported")
+
+// ErrAnalyzerFinalized is the error returned when using an Analyzer after Finalize or Close
+var ErrAnalyzerFinalized = errors.New("analyzer finalized")
+
+// ErrNoAttachmentData is the error returned when opening an Attachment that was not read from a media file
+var ErrNoAttachmentData = errors.New("attachment has no data")
+
+// OpenError is the error returned when a file can't be opened or analyzed.
+// Err is the cause, e.g. fs.ErrNotExist, fs.ErrPermission or ErrUnsupportedFormat.
+type OpenError struct {
+	Path string
+	Err  error
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("MediaInfo can't open file: %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *OpenError) Unwrap() error {
+	return e.Err
+}
+
+// OptionError is the error returned when libmediainfo rejects an option
+type OptionError struct {
+	Key      string
+	Value    string
+	Response string // acknowledgement returned by libmediainfo
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("MediaInfo rejected option %s=%q: %s", e.Key, e.Value, e.Response)
+}
+
+// ParseError is the error returned when libmediainfo output can't be parsed.
+// Snippet holds the output around the offending position.
+type ParseError struct {
+	Snippet string
+	Offset  int64
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("malformed libmediainfo output at offset %d near %q: %v", e.Offset, e.Snippet, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// snippetContext is the number of bytes kept on each side of the offending position in ParseError.Snippet
+const snippetContext = 32
+
+// newParseError builds a ParseError from a json decoding error of data
+func newParseError(data []byte, err error) *ParseError {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+
+	start, end := offset-snippetContext, offset+snippetContext
+	if start < 0 {
+		start = 0
+	}
+	if end > int64(len(data)) {
+		end = int64(len(data))
+	}
+	if start > end {
+		start = end
+	}
+	return &ParseError{Snippet: string(data[start:end]), Offset: offset, Err: err}
+}
@@ -0,0 +1,58 @@
+package mediainfo
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenError(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want error
+	}{
+		{name: "not found", path: filepath.Join("testdata", "does_not_exist.mkv"), want: os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mi := &mediaInfo{}
+			err := mi.OpenFile(tt.path)
+
+			var openErr *OpenError
+			if !errors.As(err, &openErr) {
+				t.Fatalf("OpenFile() error = %v, want *OpenError", err)
+			}
+			if openErr.Path != tt.path {
+				t.Errorf("OpenError.Path = %v, want %v", openErr.Path, tt.path)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("OpenFile() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	data := []byte(`{"media":{"@ref":"a.mkv","track":[{"@type":"General",` + strings.Repeat(" ", 64) + `"Format":}]}}`)
+	err := json.Unmarshal(data, &informStruct{})
+	if err == nil {
+		t.Fatal("json.Unmarshal() expected error")
+	}
+
+	var parseErr *ParseError
+	if !errors.As(error(newParseError(data, err)), &parseErr) {
+		t.Fatal("newParseError() is not a *ParseError")
+	}
+	if len(parseErr.Snippet) > 2*snippetContext || !strings.Contains(parseErr.Snippet, `"Format":}`) {
+		t.Errorf("ParseError.Snippet = %q", parseErr.Snippet)
+	}
+
+	var syntaxErr *json.SyntaxError
+	if !errors.As(parseErr, &syntaxErr) {
+		t.Errorf("ParseError does not unwrap to *json.SyntaxError")
+	}
+}
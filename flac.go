@@ -0,0 +1,119 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// FLAC metadata block types
+const (
+	flacStreamInfo    = 0
+	flacVorbisComment = 4
+)
+
+// probeFLAC reads a native FLAC file: STREAMINFO and the Vorbis comments
+func probeFLAC(r io.ReaderAt, size int64) (Info, error) {
+	start := id3v2Size(r)
+	magic, err := readAt(r, start, 4)
+	if err != nil || string(magic) != "fLaC" {
+		return Info{}, errNotAudioFormat
+	}
+
+	var (
+		a       Audio
+		tags    Tags
+		vendor  string
+		hasInfo bool
+	)
+	off := start + 4
+	for {
+		h, err := readAt(r, off, 4)
+		if err != nil {
+			return Info{}, err
+		}
+		last, typ := h[0]&0x80 != 0, h[0]&0x7F
+		n := int(h[1])<<16 | int(h[2])<<8 | int(h[3])
+		off += 4
+		switch typ {
+		case flacStreamInfo:
+			b, err := readAt(r, off, n)
+			if err != nil || n < 18 {
+				return Info{}, io.ErrUnexpectedEOF
+			}
+			a = flacStreamInfoAudio(b)
+			hasInfo = true
+		case flacVorbisComment:
+			b, err := readAt(r, off, n)
+			if err != nil {
+				return Info{}, err
+			}
+			vendor, tags = vorbisComments(b)
+		}
+		off += int64(n)
+		if last {
+			break
+		}
+	}
+	if !hasInfo {
+		return Info{}, errNotAudioFormat
+	}
+
+	a.StreamSize = uint(size - off)
+	if a.Duration > 0 {
+		a.BitRate = float32(float64(a.StreamSize) * 8 / float64(a.Duration))
+	}
+	info := newAudioInfo("FLAC", size, a, tags)
+	info.General.EncodedLibrary = vendor
+	return info, nil
+}
+
+// flacStreamInfoAudio decodes the STREAMINFO block b
+func flacStreamInfoAudio(b []byte) Audio {
+	// 20 bits sampling rate, 3 bits channels-1, 5 bits bit depth-1, 36 bits samples
+	rate := uint(b[10])<<12 | uint(b[11])<<4 | uint(b[12])>>4
+	a := Audio{
+		Format:          "FLAC",
+		Channels:        uint(b[12]>>1&7) + 1,
+		BitDepth:        (uint(b[12]&1)<<4 | uint(b[13])>>4) + 1,
+		SamplingRate:    rate,
+		SamplingCount:   uint(b[13]&0xF)<<32 | uint(binary.BigEndian.Uint32(b[14:18])),
+		CompressionMode: "Lossless",
+	}
+	if rate > 0 {
+		a.Duration = float32(float64(a.SamplingCount) / float64(rate))
+	}
+	return a
+}
+
+// vorbisComments decodes a Vorbis comment header (FLAC block, Vorbis and Opus comment packets without their magic)
+func vorbisComments(b []byte) (vendor string, tags Tags) {
+	next := func() (string, bool) {
+		if len(b) < 4 {
+			return "", false
+		}
+		n := int(binary.LittleEndian.Uint32(b))
+		if 4+n > len(b) {
+			return "", false
+		}
+		s := string(b[4 : 4+n])
+		b = b[4+n:]
+		return s, true
+	}
+	vendor, ok := next()
+	if !ok || len(b) < 4 {
+		return vendor, tags
+	}
+	count := binary.LittleEndian.Uint32(b)
+	b = b[4:]
+	for i := uint32(0); i < count; i++ {
+		comment, ok := next()
+		if !ok {
+			break
+		}
+		if eq := strings.IndexByte(comment, '='); eq > 0 {
+			setTag(&tags, comment[:eq], comment[eq+1:])
+		}
+	}
+	return vendor, tags
+}
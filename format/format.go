@@ -0,0 +1,170 @@
+// Package format formats mediainfo.Info values for humans, the way the
+// mediainfo command line does: "1.20 GiB", "5 950 kb/s", "1h 08mn 06s",
+// "23.976 (24000/1001) FPS", "16:9".
+//
+// The package level functions use English, other separators are available
+// through a Locale:
+//
+//	format.French.BitRate(5950000) // "5 950 kb/s"
+//	format.French.Size(1288490189) // "1,20 GiB"
+package format
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/prcoito/mediainfo"
+)
+
+// Locale represents the separators used to format numbers
+type Locale struct {
+	Decimal   string // decimal separator
+	Thousands string // thousands separator
+}
+
+// Locales available, English uses a space as thousands separator like mediainfo
+var (
+	English = Locale{Decimal: ".", Thousands: " "}
+	French  = Locale{Decimal: ",", Thousands: " "}
+	German  = Locale{Decimal: ",", Thousands: "."}
+)
+
+// Size formats a size in bytes, e.g. "1.20 GiB"
+func Size(bytes uint) string { return English.Size(bytes) }
+
+// BitRate formats a bit rate in bits per second, e.g. "5 950 kb/s"
+func BitRate(bps float32) string { return English.BitRate(bps) }
+
+// Duration formats a duration in seconds, e.g. "1h 08mn 06s"
+func Duration(seconds float32) string { return English.Duration(seconds) }
+
+// FrameRate formats a frame rate, e.g. "23.976 (24000/1001) FPS"
+func FrameRate(fps float32) string { return English.FrameRate(fps) }
+
+// AspectRatio formats a display aspect ratio, e.g. "16:9"
+func AspectRatio(ratio float32) string { return English.AspectRatio(ratio) }
+
+// SamplingRate formats a sampling rate in Hz, e.g. "48.0 kHz"
+func SamplingRate(hz uint) string { return English.SamplingRate(hz) }
+
+// Integer formats n with thousands separators, e.g. "3 840"
+func Integer(n uint) string { return English.Integer(n) }
+
+var sizeUnits = []string{"Bytes", "KiB", "MiB", "GiB", "TiB", "PiB"}
+
+// Size formats a size in bytes with 3 significant digits, e.g. "1.20 GiB" or "1000 KiB"
+func (l Locale) Size(bytes uint) string {
+	if bytes < 1024 {
+		return l.Integer(bytes) + " Bytes"
+	}
+	v := float64(bytes)
+	unit := 0
+	for v >= 1024 && unit < len(sizeUnits)-1 {
+		v /= 1024
+		unit++
+	}
+	v, decimals := significant(v, 3)
+	if v >= 1024 && unit < len(sizeUnits)-1 {
+		// e.g. 1023.7 KiB
+		v, decimals = significant(v/1024, 3)
+		unit++
+	}
+	return l.fixed(v, decimals) + " " + sizeUnits[unit]
+}
+
+// BitRate formats a bit rate in bits per second, e.g. "5 950 kb/s" or "25.0 Mb/s"
+func (l Locale) BitRate(bps float32) string {
+	switch {
+	case bps < 1000:
+		return l.Integer(uint(math.Round(float64(bps)))) + " b/s"
+	case bps < 10000000:
+		return l.Integer(uint(math.Round(float64(bps)/1000))) + " kb/s"
+	}
+	return l.fixed(float64(bps)/1000000, 1) + " Mb/s"
+}
+
+// Duration formats a duration in seconds with its two or three most significant units,
+// e.g. "1h 08mn 06s", "5mn 30s" or "30s 120ms"
+func (l Locale) Duration(seconds float32) string {
+	ms := int64(math.Round(float64(seconds) * 1000))
+	h := ms / 3600000
+	m := ms / 60000 % 60
+	s := ms / 1000 % 60
+	ms %= 1000
+
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh %02dmn %02ds", h, m, s)
+	case m > 0:
+		return fmt.Sprintf("%dmn %02ds", m, s)
+	case s > 0:
+		return fmt.Sprintf("%ds %03dms", s, ms)
+	}
+	return fmt.Sprintf("%dms", ms)
+}
+
+// FrameRate formats a frame rate, NTSC rates show their fraction, e.g. "23.976 (24000/1001) FPS"
+func (l Locale) FrameRate(fps float32) string {
+	s := l.fixed(float64(fps), 3) + " FPS"
+	if fps <= 0 {
+		return s
+	}
+	n := float64(fps) * 1.001
+	if r := math.Round(n); math.Abs(n-r) < 0.001 && math.Abs(float64(fps)-r) > 0.001 {
+		s = fmt.Sprintf("%s (%d/1001) FPS", l.fixed(float64(fps), 3), int64(r)*1000)
+	}
+	return s
+}
+
+// AspectRatio formats a display aspect ratio with its standard name if any, e.g. "16:9",
+// otherwise with 3 decimals
+func (l Locale) AspectRatio(ratio float32) string {
+	a := mediainfo.NearestAspectRatio(float64(ratio))
+	if a.Den == 0 || math.Abs(float64(ratio)-a.Float()) >= 0.01 {
+		return l.fixed(float64(ratio), 3)
+	}
+	return strings.Replace(a.Name(), ".", l.Decimal, 1)
+}
+
+// SamplingRate formats a sampling rate in Hz, e.g. "48.0 kHz"
+func (l Locale) SamplingRate(hz uint) string {
+	if hz < 1000 {
+		return l.Integer(hz) + " Hz"
+	}
+	return l.fixed(float64(hz)/1000, 1) + " kHz"
+}
+
+// Integer formats n with thousands separators, e.g. "3 840"
+func (l Locale) Integer(n uint) string {
+	s := strconv.FormatUint(uint64(n), 10)
+	if len(s) <= 3 {
+		return s
+	}
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(l.Thousands)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// fixed formats v with decimals digits after the decimal separator
+func (l Locale) fixed(v float64, decimals int) string {
+	return strings.Replace(strconv.FormatFloat(v, 'f', decimals, 64), ".", l.Decimal, 1)
+}
+
+// significant rounds v to digits significant digits, returning the number of decimals to print.
+// The integer part is kept, e.g. 1000 for 999.7.
+func significant(v float64, digits int) (float64, int) {
+	for decimals := digits - 1; decimals > 0; decimals-- {
+		p := math.Pow10(decimals)
+		if r := math.Round(v*p) / p; r < math.Pow10(digits-decimals) {
+			return r, decimals
+		}
+	}
+	return math.Round(v), 0
+}
@@ -0,0 +1,178 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prcoito/mediainfo"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "size bytes", got: Size(512), want: "512 Bytes"},
+		{name: "size 1 KiB", got: Size(1024), want: "1.00 KiB"},
+		{name: "size KiB", got: Size(1536), want: "1.50 KiB"},
+		{name: "size below 10 KiB", got: Size(10229), want: "9.99 KiB"},
+		{name: "size 10 KiB", got: Size(10235), want: "10.0 KiB"},
+		{name: "size 100 KiB", got: Size(102349), want: "100 KiB"},
+		{name: "size 1000 KiB", got: Size(1023693), want: "1000 KiB"},
+		{name: "size below 1 MiB", got: Size(1048575), want: "1.00 MiB"},
+		{name: "size MiB", got: Size(47815065), want: "45.6 MiB"},
+		{name: "size below 1 GiB", got: Size(1073217536), want: "1.00 GiB"},
+		{name: "size GiB", got: Size(1288490189), want: "1.20 GiB"},
+		{name: "bit rate b/s", got: BitRate(800), want: "800 b/s"},
+		{name: "bit rate kb/s", got: BitRate(5950123), want: "5 950 kb/s"},
+		{name: "bit rate Mb/s", got: BitRate(25012345), want: "25.0 Mb/s"},
+		{name: "duration hours", got: Duration(4086.355), want: "1h 08mn 06s"},
+		{name: "duration minutes", got: Duration(330.2), want: "5mn 30s"},
+		{name: "duration seconds", got: Duration(30.12), want: "30s 120ms"},
+		{name: "duration ms", got: Duration(0.04), want: "40ms"},
+		{name: "frame rate NTSC", got: FrameRate(23.976), want: "23.976 (24000/1001) FPS"},
+		{name: "frame rate NTSC 30", got: FrameRate(29.97), want: "29.970 (30000/1001) FPS"},
+		{name: "frame rate", got: FrameRate(25), want: "25.000 FPS"},
+		{name: "aspect ratio 16:9", got: AspectRatio(1.778), want: "16:9"},
+		{name: "aspect ratio 2.39:1", got: AspectRatio(2.387), want: "2.39:1"},
+		{name: "aspect ratio other", got: AspectRatio(1.6667), want: "1.667"},
+		{name: "sampling rate", got: SamplingRate(44100), want: "44.1 kHz"},
+		{name: "integer", got: Integer(1234567), want: "1 234 567"},
+		{name: "french size", got: French.Size(1288490189), want: "1,20 GiB"},
+		{name: "german bit rate", got: German.BitRate(5950123), want: "5.950 kb/s"},
+		{name: "french aspect ratio", got: French.AspectRatio(2.35), want: "2,35:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func testInfo() mediainfo.Info {
+	return mediainfo.Info{
+		General: mediainfo.General{
+			Format:         "Matroska",
+			FormatVersion:  "Version 4",
+			FileSize:       1288490189,
+			Duration:       4086.355,
+			OverallBitRate: 2522494,
+			Title:          "Movie",
+			CompleteName:   "/media/movie.mkv",
+		},
+		VideoTracks: []mediainfo.Video{{
+			ID:                 1,
+			Format:             "HEVC",
+			FormatProfile:      "Main 10",
+			FormatLevel:        "5.1",
+			FormatTier:         "High",
+			Width:              3840,
+			Height:             2160,
+			DisplayAspectRatio: 1.778,
+			FrameRateMode:      "CFR",
+			FrameRate:          23.976,
+			BitRate:            5950123,
+			BitDepth:           10,
+			Default:            true,
+		}},
+		AudioTracks: []mediainfo.Audio{
+			{ID: 2, Format: "E-AC-3", Channels: 6, SamplingRate: 48000, BitRate: 640000, Language: "en", Default: true},
+			{ID: 3, Format: "AAC", Channels: 2, SamplingRate: 48000, BitRate: 128000, Language: "fr"},
+		},
+		TextTracks: []mediainfo.Text{{ID: 4, Format: "UTF-8", Language: "en", Forced: true}},
+		MenuTracks: []mediainfo.Menu{{Entries: []mediainfo.Entry{
+			{StartTime: 0, Title: "Opening", Language: "en"},
+			{StartTime: 301.5, Title: "Chapter 2"},
+		}}},
+	}
+}
+
+func TestSummaries(t *testing.T) {
+	info := testInfo()
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "general", got: General(info.General), want: "Matroska, 1.20 GiB, 1h 08mn 06s, 2 522 kb/s"},
+		{name: "video", got: Video(info.VideoTracks[0]), want: "HEVC Main 10, 3840x2160 (16:9), 23.976 (24000/1001) FPS, 5 950 kb/s"},
+		{name: "audio", got: Audio(info.AudioTracks[0]), want: "E-AC-3, 6 channels, 48.0 kHz, 640 kb/s, en"},
+		{name: "text", got: Text(info.TextTracks[0]), want: "UTF-8, en, forced"},
+		{name: "menu", got: Menu(info.MenuTracks[0]), want: "2 chapters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	want := `General
+Complete name                            : /media/movie.mkv
+Format                                   : Matroska
+Format version                           : Version 4
+File size                                : 1.20 GiB
+Duration                                 : 1h 08mn 06s
+Overall bit rate                         : 2 522 kb/s
+Movie name                               : Movie
+
+Video
+ID                                       : 1
+Format                                   : HEVC
+Format profile                           : Main 10@L5.1@High
+Bit rate                                 : 5 950 kb/s
+Width                                    : 3 840 pixels
+Height                                   : 2 160 pixels
+Display aspect ratio                     : 16:9
+Frame rate mode                          : Constant
+Frame rate                               : 23.976 (24000/1001) FPS
+Bit depth                                : 10 bits
+Default                                  : Yes
+Forced                                   : No
+
+Audio #1
+ID                                       : 2
+Format                                   : E-AC-3
+Bit rate                                 : 640 kb/s
+Channel(s)                               : 6 channels
+Sampling rate                            : 48.0 kHz
+Language                                 : en
+Default                                  : Yes
+Forced                                   : No
+
+Audio #2
+ID                                       : 3
+Format                                   : AAC
+Bit rate                                 : 128 kb/s
+Channel(s)                               : 2 channels
+Sampling rate                            : 48.0 kHz
+Language                                 : fr
+Default                                  : No
+Forced                                   : No
+
+Text
+ID                                       : 4
+Format                                   : UTF-8
+Language                                 : en
+Default                                  : No
+Forced                                   : Yes
+
+Menu
+00:00:00.000                             : en:Opening
+00:05:01.500                             : Chapter 2
+
+`
+	var b strings.Builder
+	if err := Report(&b, testInfo()); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("Report()\nGot \n%s\nWant\n%s", got, want)
+	}
+}
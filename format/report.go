@@ -0,0 +1,378 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prcoito/mediainfo"
+)
+
+// General returns a one line summary of g, e.g. "Matroska, 1.20 GiB, 1h 08mn 06s, 2 480 kb/s"
+func General(g mediainfo.General) string { return English.General(g) }
+
+// Video returns a one line summary of v, e.g. "HEVC Main 10, 3840x2160 (16:9), 23.976 (24000/1001) FPS, 5 950 kb/s"
+func Video(v mediainfo.Video) string { return English.Video(v) }
+
+// Audio returns a one line summary of a, e.g. "E-AC-3, 6 channels, 48.0 kHz, 640 kb/s, en"
+func Audio(a mediainfo.Audio) string { return English.Audio(a) }
+
+// Text returns a one line summary of t, e.g. "UTF-8, en, forced"
+func Text(t mediainfo.Text) string { return English.Text(t) }
+
+// Menu returns a one line summary of m, e.g. "12 chapters"
+func Menu(m mediainfo.Menu) string { return English.Menu(m) }
+
+// Report writes info like the mediainfo command line text output
+func Report(w io.Writer, info mediainfo.Info) error { return English.Report(w, info) }
+
+// General returns a one line summary of g
+func (l Locale) General(g mediainfo.General) string {
+	return join(g.Format, l.nonZeroSize(g.FileSize), l.nonZeroDuration(g.Duration), l.nonZeroBitRate(g.OverallBitRate))
+}
+
+// Video returns a one line summary of v
+func (l Locale) Video(v mediainfo.Video) string {
+	var size string
+	if v.Width > 0 && v.Height > 0 {
+		size = fmt.Sprintf("%dx%d", v.Width, v.Height)
+		if v.DisplayAspectRatio > 0 {
+			size += " (" + l.AspectRatio(v.DisplayAspectRatio) + ")"
+		}
+	}
+	var fps string
+	if v.FrameRate > 0 {
+		fps = l.FrameRate(v.FrameRate)
+	}
+	return join(strings.TrimSpace(v.Format+" "+v.FormatProfile), size, fps, l.nonZeroBitRate(v.BitRate))
+}
+
+// Audio returns a one line summary of a
+func (l Locale) Audio(a mediainfo.Audio) string {
+	var rate string
+	if a.SamplingRate > 0 {
+		rate = l.SamplingRate(a.SamplingRate)
+	}
+	return join(a.Format, channels(a.Channels), rate, l.nonZeroBitRate(a.BitRate), a.Language)
+}
+
+// Text returns a one line summary of t
+func (l Locale) Text(t mediainfo.Text) string {
+	var forced string
+	if t.Forced {
+		forced = "forced"
+	}
+	return join(t.Format, t.Language, forced)
+}
+
+// Menu returns a one line summary of m
+func (l Locale) Menu(m mediainfo.Menu) string {
+	if len(m.Entries) == 1 {
+		return "1 chapter"
+	}
+	return fmt.Sprintf("%d chapters", len(m.Entries))
+}
+
+// Report writes info like the mediainfo command line text output
+func (l Locale) Report(w io.Writer, info mediainfo.Info) error {
+	r := &report{w: bufio.NewWriter(w), l: l}
+
+	r.section("General", 0, 1)
+	l.general(r, info.General)
+	for i, v := range info.VideoTracks {
+		r.section("Video", i, len(info.VideoTracks))
+		l.video(r, v)
+	}
+	for i, a := range info.AudioTracks {
+		r.section("Audio", i, len(info.AudioTracks))
+		l.audio(r, a)
+	}
+	for i, t := range info.TextTracks {
+		r.section("Text", i, len(info.TextTracks))
+		l.text(r, t)
+	}
+	for i, m := range info.MenuTracks {
+		r.section("Menu", i, len(info.MenuTracks))
+		l.menu(r, m)
+	}
+	r.w.WriteString("\n")
+	return r.w.Flush()
+}
+
+// labelWidth is the width of the labels column of the mediainfo command line output
+const labelWidth = 41
+
+type report struct {
+	w     *bufio.Writer
+	l     Locale
+	lines int
+}
+
+// section writes the title of a track, numbered if there are several tracks of its kind
+func (r *report) section(kind string, i, count int) {
+	if r.lines > 0 {
+		r.w.WriteString("\n")
+	}
+	if count > 1 {
+		kind += " #" + strconv.Itoa(i+1)
+	}
+	r.w.WriteString(kind + "\n")
+	r.lines++
+}
+
+// field writes a label and its value, empty values are skipped
+func (r *report) field(label, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(r.w, "%-*s: %s\n", labelWidth, label, value)
+	r.lines++
+}
+
+func (l Locale) general(r *report, g mediainfo.General) {
+	r.field("Unique ID", g.UniqueID)
+	r.field("Complete name", g.CompleteName)
+	r.field("Format", g.Format)
+	r.field("Format version", g.FormatVersion)
+	r.field("File size", l.nonZeroSize(g.FileSize))
+	r.field("Duration", l.nonZeroDuration(g.Duration))
+	r.field("Overall bit rate", l.nonZeroBitRate(g.OverallBitRate))
+	if g.FrameRate > 0 {
+		r.field("Frame rate", l.FrameRate(g.FrameRate))
+	}
+	r.field("Movie name", g.Title)
+	l.tags(r, g.Tags)
+	r.field("Encoded date", date(g.EncodedDate))
+	r.field("Writing application", g.EncodedApplication)
+	r.field("Writing library", strings.TrimSpace(g.EncodedLibrary+" "+g.EncodedLibraryVersion))
+	r.field("File creation date", date(g.FileCreatedDate))
+	r.field("File last modification date", date(g.FileModifiedDate))
+}
+
+func (l Locale) video(r *report, v mediainfo.Video) {
+	r.field("ID", l.nonZeroInteger(v.ID))
+	r.field("Format", v.Format)
+	r.field("Format profile", profile(v))
+	r.field("HDR format", v.HDRFormat)
+	r.field("Codec ID", v.CodecID)
+	r.field("Duration", l.nonZeroDuration(v.Duration))
+	r.field("Bit rate", l.nonZeroBitRate(v.BitRate))
+	if v.Width > 0 {
+		r.field("Width", l.Integer(v.Width)+" pixels")
+	}
+	if v.Height > 0 {
+		r.field("Height", l.Integer(v.Height)+" pixels")
+	}
+	if v.DisplayAspectRatio > 0 {
+		r.field("Display aspect ratio", l.AspectRatio(v.DisplayAspectRatio))
+	}
+	r.field("Frame rate mode", frameRateMode(v.FrameRateMode))
+	if v.FrameRate > 0 {
+		r.field("Frame rate", l.FrameRate(v.FrameRate))
+	}
+	r.field("Color space", v.ColorSpace)
+	r.field("Chroma subsampling", v.ChromaSubsampling)
+	if v.BitDepth > 0 {
+		r.field("Bit depth", fmt.Sprintf("%d bits", v.BitDepth))
+	}
+	r.field("Stream size", l.streamSize(v.StreamSize, v.StreamSizeProportion))
+	r.field("Title", v.Title)
+	r.field("Writing library", v.EncodedLibrary)
+	r.field("Encoding settings", v.EncodedLibrarySettings)
+	l.tags(r, v.Tags)
+	r.field("Default", yesNo(v.Default))
+	r.field("Forced", yesNo(v.Forced))
+	r.field("Color primaries", v.ColorPrimaries)
+	r.field("Transfer characteristics", v.TransferCharacteristics)
+	r.field("Matrix coefficients", v.MatrixCoefficients)
+}
+
+func (l Locale) audio(r *report, a mediainfo.Audio) {
+	r.field("ID", l.nonZeroInteger(a.ID))
+	r.field("Format", a.Format)
+	r.field("Commercial name", a.FormatCommercial)
+	r.field("Format settings", a.FormatAdditionalFeatures)
+	r.field("Codec ID", a.CodecID)
+	r.field("Duration", l.nonZeroDuration(a.Duration))
+	r.field("Bit rate", l.nonZeroBitRate(a.BitRate))
+	r.field("Channel(s)", channels(a.Channels))
+	r.field("Channel layout", a.ChannelLayout)
+	if a.SamplingRate > 0 {
+		r.field("Sampling rate", l.SamplingRate(a.SamplingRate))
+	}
+	if a.FrameRate > 0 {
+		fps := l.FrameRate(a.FrameRate)
+		if a.SamplesPerFrame > 0 {
+			fps += fmt.Sprintf(" (%d SPF)", a.SamplesPerFrame)
+		}
+		r.field("Frame rate", fps)
+	}
+	r.field("Compression mode", a.CompressionMode)
+	r.field("Stream size", l.streamSize(a.StreamSize, a.StreamSizeProportion))
+	r.field("Title", a.Title)
+	r.field("Language", a.Language)
+	l.tags(r, a.Tags)
+	r.field("Default", yesNo(a.Default))
+	r.field("Forced", yesNo(a.Forced))
+}
+
+func (l Locale) text(r *report, t mediainfo.Text) {
+	r.field("ID", l.nonZeroInteger(t.ID))
+	r.field("Format", t.Format)
+	r.field("Codec ID", t.CodecID)
+	r.field("Duration", l.nonZeroDuration(t.Duration))
+	r.field("Bit rate", l.nonZeroBitRate(t.BitRate))
+	r.field("Count of elements", l.nonZeroInteger(t.ElementCount))
+	r.field("Stream size", l.streamSize(t.StreamSize, 0))
+	r.field("Title", t.Title)
+	r.field("Language", t.Language)
+	l.tags(r, t.Tags)
+	r.field("Default", yesNo(t.Default))
+	r.field("Forced", yesNo(t.Forced))
+}
+
+func (l Locale) menu(r *report, m mediainfo.Menu) {
+	for _, e := range m.Entries {
+		title := e.Title
+		if e.Language != "" {
+			title = e.Language + ":" + title
+		}
+		start := e.StartTimeStr
+		if start == "" {
+			start = timestamp(e.StartTime)
+		}
+		r.field(start, title)
+	}
+}
+
+func (l Locale) tags(r *report, t mediainfo.Tags) {
+	r.field("Album", t.Album)
+	r.field("Album/Performer", t.AlbumPerformer)
+	r.field("Part", t.Part)
+	r.field("Part/Position", l.nonZeroInteger(t.PartPosition))
+	r.field("Part/Total", l.nonZeroInteger(t.PartTotal))
+	r.field("Track name", t.TrackName)
+	r.field("Track name/Position", l.nonZeroInteger(t.TrackPosition))
+	r.field("Track name/Total", l.nonZeroInteger(t.TrackTotal))
+	r.field("Performer", t.Performer)
+	r.field("Composer", t.Composer)
+	r.field("Publisher", t.Publisher)
+	r.field("Collection", t.Collection)
+	r.field("Season", t.Season)
+	r.field("Season/Position", l.nonZeroInteger(t.SeasonPosition))
+	r.field("Season/Total", l.nonZeroInteger(t.SeasonTotal))
+	r.field("Genre", t.Genre)
+	r.field("Content type", t.ContentType)
+	r.field("Recorded date", t.RecordedDate)
+	r.field("Description", t.Description)
+	r.field("Comment", t.Comment)
+	r.field("Copyright", t.Copyright)
+}
+
+func (l Locale) nonZeroSize(n uint) string {
+	if n == 0 {
+		return ""
+	}
+	return l.Size(n)
+}
+
+func (l Locale) nonZeroDuration(s float32) string {
+	if s <= 0 {
+		return ""
+	}
+	return l.Duration(s)
+}
+
+func (l Locale) nonZeroBitRate(bps float32) string {
+	if bps <= 0 {
+		return ""
+	}
+	return l.BitRate(bps)
+}
+
+func (l Locale) nonZeroInteger(n uint) string {
+	if n == 0 {
+		return ""
+	}
+	return l.Integer(n)
+}
+
+// streamSize formats a stream size and its proportion of the file, e.g. "1.1 GiB (92%)"
+func (l Locale) streamSize(size uint, proportion float32) string {
+	if size == 0 {
+		return ""
+	}
+	if proportion > 0 {
+		return fmt.Sprintf("%s (%.0f%%)", l.Size(size), proportion*100)
+	}
+	return l.Size(size)
+}
+
+// profile returns the profile, level and tier of v like mediainfo, e.g. "Main 10@L5.1@High"
+func profile(v mediainfo.Video) string {
+	p := v.FormatProfile
+	if p == "" {
+		return ""
+	}
+	if v.FormatLevel != "" {
+		p += "@L" + v.FormatLevel
+	}
+	if v.FormatTier != "" {
+		p += "@" + v.FormatTier
+	}
+	return p
+}
+
+func frameRateMode(mode string) string {
+	switch mode {
+	case "CFR":
+		return "Constant"
+	case "VFR":
+		return "Variable"
+	}
+	return mode
+}
+
+func channels(n uint) string {
+	switch n {
+	case 0:
+		return ""
+	case 1:
+		return "1 channel"
+	}
+	return fmt.Sprintf("%d channels", n)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("UTC 2006-01-02 15:04:05")
+}
+
+// timestamp formats seconds as hh:mm:ss.mmm
+func timestamp(seconds float32) string {
+	d := time.Duration(float64(seconds)*1000+0.5) * time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000)
+}
+
+// join joins the non empty parts with ", "
+func join(parts ...string) string {
+	var s []string
+	for _, p := range parts {
+		if p != "" {
+			s = append(s, p)
+		}
+	}
+	return strings.Join(s, ", ")
+}
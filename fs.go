@@ -0,0 +1,102 @@
+package mediainfo
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// InformFS returns the Media details (struct Info) of the file name of fsys, e.g. a file of a
+// zip archive or of an embed.FS. The seek requests of libmediainfo are followed when the file
+// implements io.ReaderAt or io.Seeker, otherwise the file is read once as by InformReader.
+// The registered Probers are used for the files implementing io.ReaderAt or io.Seeker; the
+// PostProcessors, which work on paths of the local filesystem, are not run.
+func InformFS(fsys fs.FS, name string, opts ...Option) (Info, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return Info{}, &OpenError{Path: name, Err: unwrapPathError(err)}
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return Info{}, &OpenError{Path: name, Err: unwrapPathError(err)}
+	}
+	if fi.IsDir() {
+		return Info{}, &OpenError{Path: name, Err: ErrUnsupportedFormat}
+	}
+
+	var ra io.ReaderAt
+	switch r := f.(type) {
+	case io.ReaderAt:
+		ra = r
+	case io.ReadSeeker:
+		ra = &seekReaderAt{r: r}
+	}
+
+	var info Info
+	if ra != nil {
+		var ok bool
+		if info, ok, err = probeReaderAt(ra, fi.Size(), name); err != nil {
+			return Info{}, err
+		}
+		if !ok {
+			info, err = InformReaderAt(ra, fi.Size(), opts...)
+		}
+	} else {
+		info, err = InformReader(f, fi.Size(), opts...)
+	}
+	if errors.Is(err, ErrUnsupportedFormat) {
+		return Info{}, &OpenError{Path: name, Err: ErrUnsupportedFormat}
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	info.General.CompleteName = name
+	info.General.FileExtension = strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	return info, nil
+}
+
+// WalkFSFunc is the function called by WalkFS for each file, err is the error of InformFS
+// or of reading a directory. Returning an error stops the walk, fs.SkipDir skips the
+// remaining files of the directory.
+type WalkFSFunc func(path string, info Info, err error) error
+
+// WalkFS analyzes the files of the tree root of fsys in lexical order, calling fn for each of them.
+// The files are analyzed by InformFS: for a folder of the local filesystem, filepath.Walk and Inform
+// also give the file dates, absolute paths and the results of the registered PostProcessors.
+func WalkFS(fsys fs.FS, root string, fn WalkFSFunc, opts ...Option) error {
+	return fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(path, Info{}, &OpenError{Path: path, Err: unwrapPathError(err)})
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := InformFS(fsys, path, opts...)
+		return fn(path, info, err)
+	})
+}
+
+// seekReaderAt is an io.ReaderAt over an io.ReadSeeker
+type seekReaderAt struct {
+	mu sync.Mutex
+	r  io.ReadSeeker
+}
+
+// ReadAt implements io.ReaderAt
+func (s *seekReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.r.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(s.r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,135 @@
+package mediainfo
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed testdata/1_video_1_audio_1_menu.mkv
+var testFS embed.FS
+
+func Test_seekReaderAt(t *testing.T) {
+	r := &seekReaderAt{r: bytes.NewReader([]byte("0123456789"))}
+	tests := []struct {
+		off     int64
+		n       int
+		want    string
+		wantErr error
+	}{
+		{off: 2, n: 3, want: "234"},
+		{off: 0, n: 2, want: "01"},
+		{off: 8, n: 4, want: "89", wantErr: io.EOF},
+		{off: 10, n: 1, want: "", wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		p := make([]byte, tt.n)
+		n, err := r.ReadAt(p, tt.off)
+		if string(p[:n]) != tt.want || err != tt.wantErr {
+			t.Errorf("ReadAt(%d) = %q, %v, want %q, %v", tt.off, p[:n], err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestInformFS_Errors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/a.mkv": {Data: []byte("data")},
+	}
+	tests := []struct {
+		name string
+		want error
+	}{
+		{"missing.mkv", fs.ErrNotExist},
+		{"dir", ErrUnsupportedFormat},
+		{"dir/a.mkv", ErrNotLoaded},
+	}
+	for _, tt := range tests {
+		if _, err := InformFS(fsys, tt.name); !errors.Is(err, tt.want) {
+			t.Errorf("InformFS(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestInformFS_Probers(t *testing.T) {
+	fsys := fstest.MapFS{
+		"clips/a.raws": {Data: []byte("RAWS0001")},
+	}
+	withRegistry(t, func() {
+		RegisterProber(ProbeMatch{Magic: []byte("RAWS")}, ProberFunc(func(r io.ReaderAt, size int64) (Info, error) {
+			return Info{General: General{Format: "RAWS"}}, nil
+		}))
+		got, err := InformFS(fsys, "clips/a.raws")
+		if err != nil {
+			t.Fatalf("InformFS() error = %v", err)
+		}
+		if got.General.Format != "RAWS" || got.General.CompleteName != "clips/a.raws" ||
+			got.General.FileExtension != "raws" || got.General.FileSize != 8 {
+			t.Errorf("InformFS() = %+v", got.General)
+		}
+	})
+}
+
+func TestWalkFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"b.mkv":        {Data: []byte("data")},
+		"a/1.mp4":      {Data: []byte("data")},
+		"a/2.mp4":      {Data: []byte("data")},
+		"a/sub/3.mp4":  {Data: []byte("data")},
+		"c/skip/4.mp4": {Data: []byte("data")},
+		"c/skip/5.mp4": {Data: []byte("data")},
+	}
+	var got []string
+	err := WalkFS(fsys, ".", func(path string, info Info, err error) error {
+		got = append(got, path)
+		if !errors.Is(err, ErrNotLoaded) {
+			t.Errorf("WalkFS(%s) error = %v, want %v", path, err, ErrNotLoaded)
+		}
+		if path == "c/skip/4.mp4" {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkFS() error = %v", err)
+	}
+	want := []string{"a/1.mp4", "a/2.mp4", "a/sub/3.mp4", "b.mkv", "c/skip/4.mp4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkFS() = %v, want %v", got, want)
+	}
+
+	stop := errors.New("stop")
+	if err := WalkFS(fsys, "missing", func(path string, info Info, err error) error {
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("WalkFS() error = %v, want %v", err, fs.ErrNotExist)
+		}
+		return stop
+	}); err != stop {
+		t.Errorf("WalkFS() = %v, want %v", err, stop)
+	}
+}
+
+func TestInformFS(t *testing.T) {
+	if !Load() {
+		t.Fatalf("Failed to load dll/shared object")
+	}
+	defer Unload()
+
+	name := "testdata/1_video_1_audio_1_menu.mkv"
+	want, err := Inform(name)
+	if err != nil {
+		t.Fatalf("Inform() error = %v", err)
+	}
+	got, err := InformFS(testFS, name)
+	if err != nil {
+		t.Fatalf("InformFS() error = %v", err)
+	}
+	if got.General.CompleteName != name || got.General.FileExtension != "mkv" || got.General.Format != want.General.Format ||
+		got.General.Duration != want.General.Duration || len(got.AudioTracks) != len(want.AudioTracks) {
+		t.Errorf("InformFS()\nGot \n%+v\nWant\n%+v", got, want)
+	}
+}
@@ -1,5 +1,8 @@
 module github.com/prcoito/mediainfo
 
-go 1.15
+go 1.16
 
-require golang.org/x/sys v0.0.0-20201116194326-cc9327a14d48
+require (
+	golang.org/x/sys v0.0.0-20201116194326-cc9327a14d48
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,123 @@
+package mediainfo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rss returns the resident set size of the process, 0 if unknown
+func rss() int64 {
+	b, err := ioutil.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, _ := strconv.ParseInt(fields[1], 10, 64)
+	return pages * int64(os.Getpagesize())
+}
+
+func TestMediaInfoHandle(t *testing.T) {
+	if _, err := newMediaInfo(); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("newMediaInfo() error = %v, want %v", err, ErrNotLoaded)
+	}
+	if n := liveHandles(); n != 0 {
+		t.Errorf("liveHandles() = %d, want 0", n)
+	}
+
+	if !Load() {
+		t.Fatalf("Failed to load dll/shared object")
+	}
+	defer Unload()
+
+	mi, err := newMediaInfo()
+	if err != nil {
+		t.Fatalf("newMediaInfo() error = %v", err)
+	}
+	if n := liveHandles(); n != 1 {
+		t.Errorf("liveHandles() = %d, want 1", n)
+	}
+	mi.Close()
+	mi.Close()
+	if n := liveHandles(); n != 0 {
+		t.Errorf("liveHandles() after Close = %d, want 0", n)
+	}
+
+	// the library stays loaded until the handle is deleted
+	mi, err = newMediaInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	Unload()
+	if isLoaded() {
+		t.Errorf("isLoaded() after Unload = true")
+	}
+	if v := option(mi.handle, "Info_Version", ""); v == "" {
+		t.Errorf("option() after Unload = %q", v)
+	}
+	mi.Close()
+	if !Load() {
+		t.Fatalf("Failed to reload dll/shared object")
+	}
+
+	// handles not closed are deleted by the finalizer
+	if _, err := newMediaInfo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAnalyzer(-1); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); liveHandles() != 0 && time.Now().Before(deadline); {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := liveHandles(); n != 0 {
+		t.Errorf("liveHandles() after GC = %d, want 0", n)
+	}
+}
+
+func TestInformStress(t *testing.T) {
+	if testing.Short() {
+		t.Skip("stress test")
+	}
+	if !Load() {
+		t.Fatalf("Failed to load dll/shared object")
+	}
+	defer Unload()
+
+	path := filepath.Join("testdata", "1_video_1_audio_1_menu.mkv")
+	inform := func(n int) {
+		for i := 0; i < n; i++ {
+			if _, err := Inform(path); err != nil {
+				t.Fatalf("Inform() error = %v", err)
+			}
+			if _, err := Inform(filepath.Join("testdata", "missing.mkv")); err == nil {
+				t.Fatalf("Inform() of a missing file, want error")
+			}
+		}
+	}
+
+	inform(100) // warm up
+	runtime.GC()
+	before := rss()
+	inform(2000)
+	runtime.GC()
+	after := rss()
+
+	if n := liveHandles(); n != 0 {
+		t.Errorf("liveHandles() = %d, want 0", n)
+	}
+	// a leaked handle costs tens of KiB
+	if before > 0 && after-before > 16<<20 {
+		t.Errorf("RSS grew from %d to %d bytes", before, after)
+	}
+}
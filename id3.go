@@ -0,0 +1,251 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// id3MaxSize is the size of an ID3v2 tag read, the frames after it (usually pictures) are ignored
+const id3MaxSize = 16 << 20
+
+// id3Frames maps ID3v2 text frames (v2.3/v2.4 and v2.2) to Vorbis comment names, see setTag
+var id3Frames = map[string]string{
+	"TIT2": "TITLE", "TT2": "TITLE",
+	"TALB": "ALBUM", "TAL": "ALBUM",
+	"TPE1": "ARTIST", "TP1": "ARTIST",
+	"TPE2": "ALBUMARTIST", "TP2": "ALBUMARTIST",
+	"TCOM": "COMPOSER", "TCM": "COMPOSER",
+	"TPUB": "PUBLISHER", "TPB": "PUBLISHER",
+	"TCON": "GENRE", "TCO": "GENRE",
+	"TDRC": "DATE", "TYER": "DATE", "TYE": "DATE",
+	"TRCK": "TRACKNUMBER", "TRK": "TRACKNUMBER",
+	"TPOS": "DISCNUMBER", "TPA": "DISCNUMBER",
+	"TCOP": "COPYRIGHT", "TCR": "COPYRIGHT",
+	"TSSE": "ENCODER", "TSS": "ENCODER",
+}
+
+// id3v2Size returns the size of the ID3v2 tag at the start of r, 0 if there is none
+func id3v2Size(r io.ReaderAt) int64 {
+	h, err := readAt(r, 0, 10)
+	if err != nil || string(h[:3]) != "ID3" {
+		return 0
+	}
+	size := 10 + int64(syncsafe(h[6:10]))
+	if h[5]&0x10 != 0 {
+		// footer
+		size += 10
+	}
+	return size
+}
+
+// syncsafe decodes a 28 bits ID3v2 syncsafe integer
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
+
+// readID3v2 reads the tags of the ID3v2 tag at the start of r, a file of fileSize bytes
+func readID3v2(r io.ReaderAt, fileSize int64) (Tags, error) {
+	var tags Tags
+	size := id3v2Size(r)
+	if size == 0 {
+		return tags, nil
+	}
+	if size > fileSize {
+		return tags, io.ErrUnexpectedEOF
+	}
+	if size > id3MaxSize {
+		size = id3MaxSize
+	}
+	data, err := readAt(r, 0, int(size))
+	if err != nil {
+		return tags, err
+	}
+	version, flags := data[3], data[5]
+	b := data[10:]
+	if flags&0x80 != 0 {
+		// unsynchronisation
+		b = bytes.ReplaceAll(b, []byte{0xFF, 0x00}, []byte{0xFF})
+	}
+	if flags&0x40 != 0 && version >= 3 && len(b) >= 4 {
+		// extended header
+		ext := int(binary.BigEndian.Uint32(b) + 4)
+		if version == 4 {
+			ext = int(syncsafe(b))
+		}
+		if ext > len(b) {
+			return tags, nil
+		}
+		b = b[ext:]
+	}
+
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+	for len(b) >= headerLen && b[0] != 0 {
+		id := string(b[:idLen])
+		var n int
+		switch version {
+		case 2:
+			n = int(b[3])<<16 | int(b[4])<<8 | int(b[5])
+		case 3:
+			n = int(binary.BigEndian.Uint32(b[4:8]))
+		default:
+			n = int(syncsafe(b[4:8]))
+		}
+		if headerLen+n > len(b) {
+			break
+		}
+		setID3Frame(&tags, id, b[headerLen:headerLen+n])
+		b = b[headerLen+n:]
+	}
+	return tags, nil
+}
+
+// setID3Frame sets the tag of frame id from its content
+func setID3Frame(tags *Tags, id string, frame []byte) {
+	if len(frame) < 1 {
+		return
+	}
+	enc, body := frame[0], frame[1:]
+	switch {
+	case id == "COMM" || id == "COM":
+		// language, short description, text
+		if len(body) < 3 {
+			return
+		}
+		parts := id3Strings(enc, body[3:])
+		if len(parts) >= 2 && parts[0] == "" {
+			tags.Comment = parts[1]
+		}
+	case id == "TXXX" || id == "TXX":
+		if parts := id3Strings(enc, body); len(parts) >= 2 {
+			setTag(tags, parts[0], parts[1])
+		}
+	case id3Frames[id] != "":
+		if parts := id3Strings(enc, body); len(parts) > 0 {
+			// v2.4 separates multiple values with NUL
+			v := strings.Join(parts, " / ")
+			if id == "TCON" || id == "TCO" {
+				v = id3Genre(v)
+			}
+			setTag(tags, id3Frames[id], v)
+		}
+	}
+}
+
+// id3Strings decodes the NUL separated strings of an ID3v2 frame with encoding enc
+func id3Strings(enc byte, b []byte) []string {
+	var parts []string
+	if enc == 1 || enc == 2 {
+		// UTF-16 with BOM, UTF-16BE
+		var u []uint16
+		bigEndian := enc == 2
+		for i := 0; i+1 < len(b); i += 2 {
+			c := uint16(b[i])<<8 | uint16(b[i+1])
+			if !bigEndian {
+				c = uint16(b[i+1])<<8 | uint16(b[i])
+			}
+			switch {
+			case c == 0xFEFF && len(u) == 0:
+				continue
+			case c == 0xFFFE && len(u) == 0:
+				bigEndian = !bigEndian
+				continue
+			case c == 0:
+				parts = append(parts, string(utf16.Decode(u)))
+				u = nil
+				if enc == 1 {
+					bigEndian = false
+				}
+				continue
+			}
+			u = append(u, c)
+		}
+		if len(u) > 0 {
+			parts = append(parts, string(utf16.Decode(u)))
+		}
+		return parts
+	}
+
+	for _, p := range bytes.Split(bytes.TrimRight(b, "\x00"), []byte{0}) {
+		if enc == 3 {
+			parts = append(parts, string(p))
+		} else {
+			parts = append(parts, latin1(p))
+		}
+	}
+	return parts
+}
+
+// id3Genres are the first ID3v1 genres, referenced as "(n)" or "n" in TCON
+var id3Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge", "Hip-Hop", "Jazz", "Metal",
+	"New Age", "Oldies", "Other", "Pop", "R&B", "Rap", "Reggae", "Rock", "Techno", "Industrial",
+	"Alternative", "Ska", "Death Metal", "Pranks", "Soundtrack", "Euro-Techno", "Ambient", "Trip-Hop", "Vocal", "Jazz+Funk",
+	"Fusion", "Trance", "Classical", "Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"AlternRock", "Bass", "Soul", "Punk", "Space", "Meditative", "Instrumental Pop", "Instrumental Rock", "Ethnic", "Gothic",
+	"Darkwave", "Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream", "Southern Rock", "Comedy", "Cult", "Gangsta",
+	"Top 40", "Christian Rap", "Pop/Funk", "Jungle", "Native American", "Cabaret", "New Wave", "Psychadelic", "Rave", "Showtunes",
+	"Trailer", "Lo-Fi", "Tribal", "Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll", "Hard Rock",
+}
+
+// id3Genre resolves a numeric genre reference, e.g. "(17)" or "17" is "Rock"
+func id3Genre(v string) string {
+	n := strings.TrimSuffix(strings.TrimPrefix(v, "("), ")")
+	if i := toUint(n); (i > 0 || n == "0") && int(i) < len(id3Genres) {
+		return id3Genres[i]
+	}
+	return v
+}
+
+// readID3v1 reads the ID3v1 tag at the end of r, size is 0 if there is none
+func readID3v1(r io.ReaderAt, fileSize int64) (tags Tags, size int64) {
+	if fileSize < 128 {
+		return tags, 0
+	}
+	b, err := readAt(r, fileSize-128, 128)
+	if err != nil || string(b[:3]) != "TAG" {
+		return tags, 0
+	}
+	field := func(b []byte) string {
+		return strings.TrimSpace(latin1(bytes.TrimRight(b, "\x00 ")))
+	}
+	tags.TrackName = field(b[3:33])
+	tags.Performer = field(b[33:63])
+	tags.Album = field(b[63:93])
+	tags.RecordedDate = field(b[93:97])
+	if b[125] == 0 && b[126] != 0 {
+		// ID3v1.1
+		tags.Comment = field(b[97:125])
+		tags.TrackPosition = uint(b[126])
+	} else {
+		tags.Comment = field(b[97:127])
+	}
+	if int(b[127]) < len(id3Genres) {
+		tags.Genre = id3Genres[b[127]]
+	}
+	return tags, 128
+}
+
+// mergeTags returns t with the empty fields set from fallback (e.g. ID3v2 over ID3v1)
+func mergeTags(t, fallback Tags) Tags {
+	set := func(v *string, f string) {
+		if *v == "" {
+			*v = f
+		}
+	}
+	set(&t.TrackName, fallback.TrackName)
+	set(&t.Performer, fallback.Performer)
+	set(&t.Album, fallback.Album)
+	set(&t.RecordedDate, fallback.RecordedDate)
+	set(&t.Comment, fallback.Comment)
+	set(&t.Genre, fallback.Genre)
+	if t.TrackPosition == 0 {
+		t.TrackPosition = fallback.TrackPosition
+	}
+	return t
+}
@@ -0,0 +1,258 @@
+// Package manifest derives HLS and DASH manifest attributes from mediainfo.Info:
+// RFC 6381 codecs, bandwidth, resolution, frame rate, video range and channels.
+//
+//	line, err := manifest.StreamInf(info)
+//	// #EXT-X-STREAM-INF:BANDWIDTH=6720000,AVERAGE-BANDWIDTH=6720000,CODECS="hvc1.2.4.L153.B0,ec-3",RESOLUTION=3840x2160,FRAME-RATE=23.976,VIDEO-RANGE=PQ
+package manifest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/prcoito/mediainfo"
+)
+
+// Video ranges of HLS VIDEO-RANGE
+const (
+	VideoRangeSDR = "SDR"
+	VideoRangePQ  = "PQ"
+	VideoRangeHLG = "HLG"
+)
+
+// VideoRange returns the HLS VIDEO-RANGE of v: PQ for HDR10 and Dolby Vision,
+// HLG for hybrid log-gamma, SDR otherwise
+func VideoRange(v mediainfo.Video) string {
+	switch t := v.TransferCharacteristics; {
+	case t == "PQ" || strings.Contains(t, "2084"):
+		return VideoRangePQ
+	case t == "HLG" || strings.Contains(t, "B67"):
+		return VideoRangeHLG
+	case strings.Contains(v.HDRFormat, "Dolby Vision") || strings.Contains(v.HDRFormat, "2086") || strings.Contains(v.HDRFormat, "2094"):
+		return VideoRangePQ
+	}
+	return VideoRangeSDR
+}
+
+// Resolution returns the HLS RESOLUTION of v, e.g. "1920x1080", empty if unknown
+func Resolution(v mediainfo.Video) string {
+	if v.Width == 0 || v.Height == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", v.Width, v.Height)
+}
+
+// FrameRate returns the HLS FRAME-RATE of v with 3 decimals, e.g. "23.976", empty if unknown
+func FrameRate(v mediainfo.Video) string {
+	if v.FrameRate <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(v.FrameRate), 'f', 3, 32)
+}
+
+// DASHFrameRate returns the DASH frameRate of v: a fraction for NTSC rates (e.g. "24000/1001"),
+// an integer or a decimal number otherwise, empty if unknown
+func DASHFrameRate(v mediainfo.Video) string {
+	fps := float64(v.FrameRate)
+	if fps <= 0 {
+		return ""
+	}
+	if r := math.Round(fps); math.Abs(fps-r) < 0.001 {
+		return strconv.Itoa(int(r))
+	}
+	if n := math.Round(fps * 1.001); math.Abs(fps*1.001-n) < 0.001 {
+		return fmt.Sprintf("%d/1001", int(n)*1000)
+	}
+	return strconv.FormatFloat(fps, 'f', -1, 32)
+}
+
+// Channels returns the HLS CHANNELS of a, e.g. "6", empty if unknown
+func Channels(a mediainfo.Audio) string {
+	if a.Channels == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(a.Channels))
+}
+
+// Bandwidth returns the bandwidth of info in bits per second: the overall bit rate,
+// or the sum of the bit rates of the video and audio tracks if unknown
+func Bandwidth(info mediainfo.Info) uint {
+	if info.General.OverallBitRate > 0 {
+		return uint(math.Round(float64(info.General.OverallBitRate)))
+	}
+	var sum float64
+	for _, v := range info.VideoTracks {
+		sum += float64(v.BitRate)
+	}
+	for _, a := range info.AudioTracks {
+		sum += float64(a.BitRate)
+	}
+	return uint(math.Round(sum))
+}
+
+// Codecs returns the RFC 6381 codecs of the video and audio tracks of info, without duplicates
+func Codecs(info mediainfo.Info) ([]string, error) {
+	var codecs []string
+	seen := map[string]bool{}
+	add := func(c string, err error) error {
+		if err != nil {
+			return err
+		}
+		if !seen[c] {
+			seen[c] = true
+			codecs = append(codecs, c)
+		}
+		return nil
+	}
+
+	for _, v := range info.VideoTracks {
+		if err := add(v.CodecString()); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range info.AudioTracks {
+		if err := add(a.CodecString()); err != nil {
+			return nil, err
+		}
+	}
+	return codecs, nil
+}
+
+// StreamInf returns the #EXT-X-STREAM-INF tag of a variant stream muxing the tracks of info.
+// Bandwidth is used for both BANDWIDTH and AVERAGE-BANDWIDTH as the peak bit rate is unknown.
+func StreamInf(info mediainfo.Info) (string, error) {
+	codecs, err := Codecs(info)
+	if err != nil {
+		return "", err
+	}
+
+	bandwidth := strconv.FormatUint(uint64(Bandwidth(info)), 10)
+	attrs := []string{
+		"BANDWIDTH=" + bandwidth,
+		"AVERAGE-BANDWIDTH=" + bandwidth,
+		`CODECS="` + strings.Join(codecs, ",") + `"`,
+	}
+	if len(info.VideoTracks) > 0 {
+		v := info.VideoTracks[0]
+		if r := Resolution(v); r != "" {
+			attrs = append(attrs, "RESOLUTION="+r)
+		}
+		if f := FrameRate(v); f != "" {
+			attrs = append(attrs, "FRAME-RATE="+f)
+		}
+		attrs = append(attrs, "VIDEO-RANGE="+VideoRange(v))
+	}
+	return "#EXT-X-STREAM-INF:" + strings.Join(attrs, ","), nil
+}
+
+// Media returns the #EXT-X-MEDIA tag of an audio rendition a in group groupID
+func Media(a mediainfo.Audio, groupID, name string) string {
+	attrs := []string{"TYPE=AUDIO", `GROUP-ID="` + groupID + `"`}
+	if a.Language != "" {
+		attrs = append(attrs, `LANGUAGE="`+a.Language+`"`)
+	}
+	attrs = append(attrs, `NAME="`+name+`"`)
+	if a.Default {
+		attrs = append(attrs, "DEFAULT=YES")
+	}
+	attrs = append(attrs, "AUTOSELECT=YES")
+	if c := Channels(a); c != "" {
+		attrs = append(attrs, `CHANNELS="`+c+`"`)
+	}
+	return "#EXT-X-MEDIA:" + strings.Join(attrs, ",")
+}
+
+// audioChannelScheme is the DASH scheme of AudioChannelConfiguration values as a channel count
+const audioChannelScheme = "urn:mpeg:dash:23003:3:audio_channel_configuration:2011"
+
+// Representation represents a DASH <Representation> of a single track
+type Representation struct {
+	XMLName           xml.Name                   `xml:"Representation"`
+	ID                string                     `xml:"id,attr"`
+	MimeType          string                     `xml:"mimeType,attr,omitempty"`
+	Codecs            string                     `xml:"codecs,attr"`
+	Bandwidth         uint                       `xml:"bandwidth,attr"`
+	Width             uint                       `xml:"width,attr,omitempty"`
+	Height            uint                       `xml:"height,attr,omitempty"`
+	FrameRate         string                     `xml:"frameRate,attr,omitempty"`
+	SAR               string                     `xml:"sar,attr,omitempty"`
+	AudioSamplingRate uint                       `xml:"audioSamplingRate,attr,omitempty"`
+	ChannelConfig     *AudioChannelConfiguration `xml:"AudioChannelConfiguration,omitempty"`
+}
+
+// AudioChannelConfiguration represents the DASH <AudioChannelConfiguration> of an audio Representation
+type AudioChannelConfiguration struct {
+	SchemeIDURI string `xml:"schemeIdUri,attr"`
+	Value       string `xml:"value,attr"`
+}
+
+func (r Representation) String() string {
+	b, err := xml.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// VideoRepresentation returns the DASH Representation of v
+func VideoRepresentation(id string, v mediainfo.Video) (Representation, error) {
+	codecs, err := v.CodecString()
+	if err != nil {
+		return Representation{}, err
+	}
+	r := Representation{
+		ID:        id,
+		MimeType:  "video/mp4",
+		Codecs:    codecs,
+		Bandwidth: uint(math.Round(float64(v.BitRate))),
+		Width:     v.Width,
+		Height:    v.Height,
+		FrameRate: DASHFrameRate(v),
+	}
+	if !v.IsAnamorphic() {
+		r.SAR = "1:1"
+	}
+	return r, nil
+}
+
+// AudioRepresentation returns the DASH Representation of a
+func AudioRepresentation(id string, a mediainfo.Audio) (Representation, error) {
+	codecs, err := a.CodecString()
+	if err != nil {
+		return Representation{}, err
+	}
+	r := Representation{
+		ID:                id,
+		MimeType:          "audio/mp4",
+		Codecs:            codecs,
+		Bandwidth:         uint(math.Round(float64(a.BitRate))),
+		AudioSamplingRate: a.SamplingRate,
+	}
+	if c := Channels(a); c != "" {
+		r.ChannelConfig = &AudioChannelConfiguration{SchemeIDURI: audioChannelScheme, Value: c}
+	}
+	return r, nil
+}
+
+// Representations returns the DASH Representations of the video and audio tracks of info,
+// identified by their track ID
+func Representations(info mediainfo.Info) ([]Representation, error) {
+	var reps []Representation
+	for _, v := range info.VideoTracks {
+		r, err := VideoRepresentation(strconv.Itoa(int(v.ID)), v)
+		if err != nil {
+			return nil, err
+		}
+		reps = append(reps, r)
+	}
+	for _, a := range info.AudioTracks {
+		r, err := AudioRepresentation(strconv.Itoa(int(a.ID)), a)
+		if err != nil {
+			return nil, err
+		}
+		reps = append(reps, r)
+	}
+	return reps, nil
+}
@@ -0,0 +1,115 @@
+package manifest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prcoito/mediainfo"
+)
+
+func uhdInfo() mediainfo.Info {
+	return mediainfo.Info{
+		General: mediainfo.General{OverallBitRate: 6720000},
+		VideoTracks: []mediainfo.Video{{
+			ID:                      1,
+			Format:                  "HEVC",
+			CodecID:                 "hvc1",
+			FormatProfile:           "Main 10",
+			FormatLevel:             "5.1",
+			FormatTier:              "Main",
+			Width:                   3840,
+			Height:                  2160,
+			PixelAspectRatio:        1,
+			FrameRate:               23.976,
+			BitDepth:                10,
+			BitRate:                 6000000,
+			HDRFormat:               "SMPTE ST 2086",
+			TransferCharacteristics: "PQ",
+		}},
+		AudioTracks: []mediainfo.Audio{
+			{ID: 2, Format: "E-AC-3", CodecID: "ec-3", Channels: 6, SamplingRate: 48000, BitRate: 640000, Language: "en", Default: true},
+			{ID: 3, Format: "AAC", FormatAdditionalFeatures: "LC", CodecID: "mp4a-40-2", Channels: 2, SamplingRate: 48000, BitRate: 80000, Language: "en"},
+		},
+	}
+}
+
+func TestStreamInf(t *testing.T) {
+	got, err := StreamInf(uhdInfo())
+	if err != nil {
+		t.Fatalf("StreamInf() error = %v", err)
+	}
+	want := `#EXT-X-STREAM-INF:BANDWIDTH=6720000,AVERAGE-BANDWIDTH=6720000,CODECS="hvc1.2.4.L153.B0,ec-3,mp4a.40.2",RESOLUTION=3840x2160,FRAME-RATE=23.976,VIDEO-RANGE=PQ`
+	if got != want {
+		t.Errorf("StreamInf()\nGot  %s\nWant %s", got, want)
+	}
+
+	info := uhdInfo()
+	info.VideoTracks[0].FormatProfile = ""
+	if _, err := StreamInf(info); !errors.Is(err, mediainfo.ErrNoCodecString) {
+		t.Errorf("StreamInf() error = %v, want ErrNoCodecString", err)
+	}
+}
+
+func TestMedia(t *testing.T) {
+	got := Media(uhdInfo().AudioTracks[0], "audio", "English 5.1")
+	want := `#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",LANGUAGE="en",NAME="English 5.1",DEFAULT=YES,AUTOSELECT=YES,CHANNELS="6"`
+	if got != want {
+		t.Errorf("Media()\nGot  %s\nWant %s", got, want)
+	}
+}
+
+func TestRepresentations(t *testing.T) {
+	reps, err := Representations(uhdInfo())
+	if err != nil {
+		t.Fatalf("Representations() error = %v", err)
+	}
+	want := []string{
+		`<Representation id="1" mimeType="video/mp4" codecs="hvc1.2.4.L153.B0" bandwidth="6000000" width="3840" height="2160" frameRate="24000/1001" sar="1:1"></Representation>`,
+		`<Representation id="2" mimeType="audio/mp4" codecs="ec-3" bandwidth="640000" audioSamplingRate="48000"><AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="6"></AudioChannelConfiguration></Representation>`,
+		`<Representation id="3" mimeType="audio/mp4" codecs="mp4a.40.2" bandwidth="80000" audioSamplingRate="48000"><AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="2"></AudioChannelConfiguration></Representation>`,
+	}
+	if len(reps) != len(want) {
+		t.Fatalf("Representations() returned %d, want %d", len(reps), len(want))
+	}
+	for i := range want {
+		if got := reps[i].String(); got != want[i] {
+			t.Errorf("Representations()[%d]\nGot  %s\nWant %s", i, got, want[i])
+		}
+	}
+}
+
+func TestVideoRange(t *testing.T) {
+	tests := []struct {
+		video mediainfo.Video
+		want  string
+	}{
+		{mediainfo.Video{TransferCharacteristics: "BT.709"}, VideoRangeSDR},
+		{mediainfo.Video{TransferCharacteristics: "PQ"}, VideoRangePQ},
+		{mediainfo.Video{TransferCharacteristics: "HLG"}, VideoRangeHLG},
+		{mediainfo.Video{HDRFormat: "Dolby Vision"}, VideoRangePQ},
+		{mediainfo.Video{}, VideoRangeSDR},
+	}
+	for _, tt := range tests {
+		if got := VideoRange(tt.video); got != tt.want {
+			t.Errorf("VideoRange(%+v) = %v, want %v", tt.video, got, tt.want)
+		}
+	}
+}
+
+func TestDASHFrameRate(t *testing.T) {
+	tests := []struct {
+		fps  float32
+		want string
+	}{
+		{23.976, "24000/1001"},
+		{29.97, "30000/1001"},
+		{25, "25"},
+		{12.5, "12.5"},
+		{0, ""},
+	}
+	for _, tt := range tests {
+		if got := DASHFrameRate(mediainfo.Video{FrameRate: tt.fps}); got != tt.want {
+			t.Errorf("DASHFrameRate(%v) = %q, want %q", tt.fps, got, tt.want)
+		}
+	}
+}
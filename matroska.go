@@ -0,0 +1,224 @@
+package mediainfo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Matroska/EBML element IDs
+const (
+	ebmlIDHeader        = 0x1A45DFA3
+	mkvIDSegment        = 0x18538067
+	mkvIDSeekHead       = 0x114D9B74
+	mkvIDSeek           = 0x4DBB
+	mkvIDSeekID         = 0x53AB
+	mkvIDSeekPosition   = 0x53AC
+	mkvIDAttachments    = 0x1941A469
+	mkvIDAttachedFile   = 0x61A7
+	mkvIDFileName       = 0x466E
+	mkvIDFileMimeType   = 0x4660
+	mkvIDFileDesc       = 0x467E
+	mkvIDFileData       = 0x465C
+	mkvIDFileUID        = 0x46AE
+	ebmlUnknownSize     = -1
+	ebmlMaxHeaderLength = 12 // 4 bytes ID + 8 bytes size
+)
+
+// vintUnknown is returned by readVint for sizes with all bits set (unknown size)
+const vintUnknown = ^uint64(0)
+
+var errInvalidEBML = errors.New("invalid EBML data")
+
+// ebmlElement represents an EBML element header read at Offset
+type ebmlElement struct {
+	ID         uint32
+	Offset     int64 // offset of the element
+	DataOffset int64 // offset of the element data
+	Size       int64 // size of the element data, ebmlUnknownSize if unknown
+}
+
+// end returns the offset after the element, limit if its size is unknown
+func (e ebmlElement) end(limit int64) int64 {
+	if e.Size == ebmlUnknownSize || e.DataOffset+e.Size > limit {
+		return limit
+	}
+	return e.DataOffset + e.Size
+}
+
+// readVint reads an EBML variable size integer from b.
+// The length marker is kept when keepMarker is set (element IDs).
+func readVint(b []byte, keepMarker bool) (v uint64, n int, err error) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0, errInvalidEBML
+	}
+
+	n = 1
+	for mask := byte(0x80); b[0]&mask == 0; mask >>= 1 {
+		n++
+	}
+	if n > len(b) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+
+	v = uint64(b[0])
+	if !keepMarker {
+		v &= uint64(0xFF >> uint(n))
+	}
+	allOnes := v == uint64(0xFF>>uint(n))
+	for i := 1; i < n; i++ {
+		v = v<<8 | uint64(b[i])
+		allOnes = allOnes && b[i] == 0xFF
+	}
+	if !keepMarker && allOnes {
+		return vintUnknown, n, nil
+	}
+	return v, n, nil
+}
+
+// readEBMLElement reads the element header at off
+func readEBMLElement(r io.ReaderAt, off int64) (ebmlElement, error) {
+	b := make([]byte, ebmlMaxHeaderLength)
+	n, err := r.ReadAt(b, off)
+	if n == 0 && err != nil {
+		return ebmlElement{}, err
+	}
+	b = b[:n]
+
+	id, idLen, err := readVint(b, true)
+	if err != nil || idLen > 4 {
+		return ebmlElement{}, errInvalidEBML
+	}
+	size, sizeLen, err := readVint(b[idLen:], false)
+	if err != nil {
+		return ebmlElement{}, errInvalidEBML
+	}
+
+	e := ebmlElement{ID: uint32(id), Offset: off, DataOffset: off + int64(idLen+sizeLen), Size: int64(size)}
+	if size == vintUnknown {
+		e.Size = ebmlUnknownSize
+	}
+	return e, nil
+}
+
+// ebmlChildren calls fn for each child of the data between start and end.
+// Iteration stops when fn returns false or at the first child of unknown size.
+func ebmlChildren(r io.ReaderAt, start, end int64, fn func(ebmlElement) (bool, error)) error {
+	for off := start; off < end; {
+		e, err := readEBMLElement(r, off)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		more, err := fn(e)
+		if err != nil || !more || e.Size == ebmlUnknownSize {
+			return err
+		}
+		off = e.end(end)
+	}
+	return nil
+}
+
+func readEBMLUint(r io.ReaderAt, e ebmlElement) (uint64, error) {
+	if e.Size > 8 || e.Size < 0 {
+		return 0, errInvalidEBML
+	}
+	b := make([]byte, e.Size)
+	if _, err := r.ReadAt(b, e.DataOffset); err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// maxEBMLString is the maximum size of the strings read (names, mime types...)
+const maxEBMLString = 64 * 1024
+
+func readEBMLString(r io.ReaderAt, e ebmlElement) (string, error) {
+	if e.Size > maxEBMLString || e.Size < 0 {
+		return "", errInvalidEBML
+	}
+	b := make([]byte, e.Size)
+	if _, err := r.ReadAt(b, e.DataOffset); err != nil {
+		return "", err
+	}
+	// strings may be zero padded
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return string(b), nil
+}
+
+// matroskaSegment returns the Segment element of a Matroska/WebM file of size bytes
+func matroskaSegment(r io.ReaderAt, size int64) (ebmlElement, error) {
+	header, err := readEBMLElement(r, 0)
+	if err != nil || header.ID != ebmlIDHeader || header.Size == ebmlUnknownSize {
+		return ebmlElement{}, fmt.Errorf("not a Matroska file: %w", errInvalidEBML)
+	}
+
+	segment, err := readEBMLElement(r, header.end(size))
+	if err != nil || segment.ID != mkvIDSegment {
+		return ebmlElement{}, fmt.Errorf("Matroska segment not found: %w", errInvalidEBML)
+	}
+	return segment, nil
+}
+
+// matroskaTopLevel returns the offset of the first top level element with id inside segment,
+// using the SeekHead when present. It returns -1 if not found.
+func matroskaTopLevel(r io.ReaderAt, segment ebmlElement, size int64, id uint32) (int64, error) {
+	end := segment.end(size)
+	found := int64(-1)
+
+	err := ebmlChildren(r, segment.DataOffset, end, func(e ebmlElement) (bool, error) {
+		switch e.ID {
+		case id:
+			found = e.Offset
+			return false, nil
+		case mkvIDSeekHead:
+			pos, err := matroskaSeek(r, e, end, id)
+			if err != nil || pos < 0 {
+				return true, err
+			}
+			found = segment.DataOffset + pos
+			return false, nil
+		}
+		return true, nil
+	})
+	return found, err
+}
+
+// matroskaSeek returns the position (relative to the segment data) of id in seekHead, -1 if not present
+func matroskaSeek(r io.ReaderAt, seekHead ebmlElement, limit int64, id uint32) (int64, error) {
+	pos := int64(-1)
+	err := ebmlChildren(r, seekHead.DataOffset, seekHead.end(limit), func(seek ebmlElement) (bool, error) {
+		if seek.ID != mkvIDSeek {
+			return true, nil
+		}
+
+		var seekID, seekPos uint64
+		err := ebmlChildren(r, seek.DataOffset, seek.end(limit), func(e ebmlElement) (bool, error) {
+			var err error
+			switch e.ID {
+			case mkvIDSeekID:
+				seekID, err = readEBMLUint(r, e)
+			case mkvIDSeekPosition:
+				seekPos, err = readEBMLUint(r, e)
+			}
+			return true, err
+		})
+		if err != nil {
+			return false, err
+		}
+		if uint32(seekID) == id {
+			pos = int64(seekPos)
+			return false, nil
+		}
+		return true, nil
+	})
+	return pos, err
+}
@@ -3,34 +3,77 @@ package mediainfo
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// Inform returns the Media details (struct Info) from file f
-func Inform(f string) (r Info, err error) {
+// Inform returns the Media details (struct Info) from file f.
+// opts are libmediainfo options applied to this call only.
+// The registered Probers matching f are used before libmediainfo (and the native audio parsers
+// with WithNativeAudio) and the registered PostProcessors are run on the result
+// (see RegisterProber and RegisterPostProcessor).
+func Inform(f string, opts ...Option) (r Info, err error) {
 	f, _ = filepath.Abs(f) // set here to avoid short path representation in windows
 
-	mi, err := newMediaInfo()
+	o, err := newOptions(opts)
 	if err != nil {
 		return
 	}
 
-	defer mi.Close()
-	err = mi.OpenFile(f)
-	if err != nil {
+	var ok bool
+	if r, ok, err = probe(f); err != nil {
 		return
 	}
+	if !ok && o.nativeAudio {
+		// the files the native parsers can't read are analyzed by libmediainfo
+		if info, err := InformAudio(f); err == nil {
+			r, ok = info, true
+		}
+	}
+	if !ok {
+		var info informStruct
+		if info, err = informFile(f, o); err != nil {
+			return
+		}
+
+		r.General.CompleteName = f
+		r.ScanMode = o.scanMode()
+
+		if !hasFormat(info) {
+			return r, &OpenError{Path: f, Err: ErrUnsupportedFormat}
+		}
+
+		r.setTracks(info)
+	}
+
+	err = postProcess(f, &r)
+	return
+}
 
-	info, err := mi.Inform()
+// informFile returns the libmediainfo output for file f
+func informFile(f string, o *Options) (informStruct, error) {
+	mi, err := newMediaInfo()
 	if err != nil {
-		return
+		return informStruct{}, err
 	}
 
-	r.General.CompleteName = f
+	defer mi.Close()
+	if err = mi.SetOptions(o); err != nil {
+		return informStruct{}, err
+	}
 
+	if err = mi.OpenFile(f); err != nil {
+		return informStruct{}, err
+	}
+
+	return mi.Inform()
+}
+
+// setTracks fills r with the tracks present in info
+func (r *Info) setTracks(info informStruct) {
 	for _, track := range info.Media.Tracks {
 		switch track.Type {
 		case "General":
@@ -43,11 +86,13 @@ func Inform(f string) (r Info, err error) {
 			r.General.FileExtension = strings.ToLower(track.FileExtension)
 			r.General.Format = track.Format
 			r.General.FormatVersion = track.FormatVersion
+			r.General.FormatProfile = track.FormatProfile
 			r.General.FileSize = toUint(track.FileSize)
 			r.General.OverallBitRate = toFloat(track.OverallBitRate)
 			r.General.FrameRate = toFloat(track.FrameRate)
 			r.General.FrameCount = toUint(track.FrameCount)
 			r.General.IsStreamable = toBool(track.IsStreamable)
+			r.General.NotInterleaved = track.Interleaved == "No"
 			r.General.EncodedDate = toTime(track.EncodedDate)
 			r.General.FileCreatedDate = toTime(track.FileCreatedDate)
 			r.General.FileModifiedDate = toTime(track.FileModifiedDate)
@@ -55,40 +100,48 @@ func Inform(f string) (r Info, err error) {
 			r.General.EncodedLibrary = fmt.Sprintf("%s", track.EncodedLibrary)
 			r.General.EncodedLibraryVersion = track.EncodedLibraryVersion
 			r.General.Title = track.Title
+			r.General.Tags = newTags(track)
 		case "Video":
 			r.VideoTracks = append(r.VideoTracks, Video{
-				StreamOrder:            toUint(track.StreamOrder),
-				ID:                     toUint(track.ID),
-				UniqueID:               track.UniqueID,
-				Format:                 track.Format,
-				FormatProfile:          track.FormatProfile,
-				FormatLevel:            track.FormatLevel,
-				FormatTier:             track.FormatTier,
-				CodecID:                track.CodecID,
-				Duration:               toFloat(track.Duration),
-				BitRate:                toFloat(track.BitRate),
-				Width:                  toUint(track.Width),
-				Height:                 toUint(track.Height),
-				SampledWidth:           toUint(track.SampledWidth),
-				SampledHeight:          toUint(track.Height),
-				PixelAspectRatio:       toFloat(track.PixelAspectRatio),
-				DisplayAspectRatio:     toFloat(track.DisplayAspectRatio),
-				FrameRateMode:          track.FrameRateMode,
-				FrameRate:              toFloat(track.FrameRate),
-				FrameCount:             toUint(track.FrameCount),
-				ColorSpace:             track.ColorSpace,
-				ChromaSubsampling:      track.ChromaSubsampling,
-				BitDepth:               toUint(track.BitDepth),
-				StreamSize:             toUint(track.StreamSize),
-				StreamSizeProportion:   toFloat(track.StreamSizeProportion),
-				EncodedLibrary:         fmt.Sprintf("%s", track.EncodedLibrary),
-				EncodedLibraryName:     track.EncodedLibraryName,
-				EncodedLibraryVersion:  track.EncodedLibraryVersion,
-				EncodedLibrarySettings: track.EncodedLibrarySettings,
-				Default:                toBool(track.Default),
-				Forced:                 toBool(track.Forced),
-				B3D:                    track.MultiViewCount != "",
-				Title:                  track.Title,
+				StreamOrder:             toUint(track.StreamOrder),
+				ID:                      toUint(track.ID),
+				UniqueID:                track.UniqueID,
+				Format:                  track.Format,
+				FormatProfile:           track.FormatProfile,
+				FormatLevel:             track.FormatLevel,
+				FormatTier:              track.FormatTier,
+				CodecID:                 track.CodecID,
+				Duration:                toFloat(track.Duration),
+				BitRate:                 toFloat(track.BitRate),
+				Width:                   toUint(track.Width),
+				Height:                  toUint(track.Height),
+				SampledWidth:            toUint(track.SampledWidth),
+				SampledHeight:           toUint(track.Height),
+				ActiveWidth:             toUint(track.ActiveWidth),
+				ActiveHeight:            toUint(track.ActiveHeight),
+				PixelAspectRatio:        toFloat(track.PixelAspectRatio),
+				DisplayAspectRatio:      toFloat(track.DisplayAspectRatio),
+				FrameRateMode:           track.FrameRateMode,
+				FrameRate:               toFloat(track.FrameRate),
+				FrameCount:              toUint(track.FrameCount),
+				ColorSpace:              track.ColorSpace,
+				ChromaSubsampling:       track.ChromaSubsampling,
+				BitDepth:                toUint(track.BitDepth),
+				HDRFormat:               track.HDRFormat,
+				ColorPrimaries:          track.ColorPrimaries,
+				TransferCharacteristics: track.TransferCharacteristics,
+				MatrixCoefficients:      track.MatrixCoefficients,
+				StreamSize:              toUint(track.StreamSize),
+				StreamSizeProportion:    toFloat(track.StreamSizeProportion),
+				EncodedLibrary:          fmt.Sprintf("%s", track.EncodedLibrary),
+				EncodedLibraryName:      track.EncodedLibraryName,
+				EncodedLibraryVersion:   track.EncodedLibraryVersion,
+				EncodedLibrarySettings:  track.EncodedLibrarySettings,
+				Default:                 toBool(track.Default),
+				Forced:                  toBool(track.Forced),
+				B3D:                     track.MultiViewCount != "",
+				Title:                   track.Title,
+				Tags:                    newTags(track),
 			})
 		case "Audio":
 			r.AudioTracks = append(r.AudioTracks, Audio{
@@ -104,6 +157,7 @@ func Inform(f string) (r Info, err error) {
 				Format:                   track.Format,
 				FormatAdditionalFeatures: track.FormatAdditionalFeatures,
 				FormatCommercial:         track.FormatCommercial,
+				FormatProfile:            track.FormatProfile,
 				FrameCount:               toUint(track.FrameCount),
 				FrameRate:                toFloat(track.FrameRate),
 				ID:                       toUint(track.ID),
@@ -111,11 +165,13 @@ func Inform(f string) (r Info, err error) {
 				SamplesPerFrame:          toUint(track.SamplesPerFrame),
 				SamplingCount:            toUint(track.SamplingCount),
 				SamplingRate:             toUint(track.SamplingRate),
+				BitDepth:                 toUint(track.BitDepth),
 				StreamOrder:              toUint(track.StreamOrder),
 				StreamSize:               toUint(track.StreamSize),
 				StreamSizeProportion:     toFloat(track.StreamSizeProportion),
 				UniqueID:                 track.UniqueID,
 				Title:                    track.Title,
+				Tags:                     newTags(track),
 			})
 		case "Text":
 			r.TextTracks = append(r.TextTracks, Text{
@@ -134,10 +190,25 @@ func Inform(f string) (r Info, err error) {
 				Order:        toUint(track.TypeOrder),
 				UniqueID:     track.UniqueID,
 				Title:        track.Title,
+				Tags:         newTags(track),
 			})
 		case "Menu":
+			if track.MenuID != "" {
+				// MPEG-TS program
+				r.Programs = append(r.Programs, Program{
+					Number:          toUint(track.MenuID),
+					PMTPID:          toUint(track.ID),
+					ServiceName:     track.ServiceName,
+					ServiceProvider: track.ServiceProvider,
+					PIDs:            parsePIDList(track.List),
+				})
+				continue
+			}
+
+			// libmediainfo reports each edition as a different menu track
 			m := Menu{
 				Order:    toUint(track.TypeOrder),
+				Edition:  uint(len(r.MenuTracks)),
 				Duration: r.General.Duration,
 			}
 
@@ -147,26 +218,16 @@ func Inform(f string) (r Info, err error) {
 					continue
 				}
 				// k = time
-				// v = lang:title
+				// v = lang:title or lang:title - lang:title
 				ss := formatExtra(k)
-				st := formatTime(ss)
-				idx := strings.Index(v, ":")
-
-				var language, title string
-				if idx < 0 {
-					language = track.Language // default to track info if present
-					title = v
-				} else {
-					language = v[:idx]
-					title = v[idx+1:]
+				e := Entry{
+					StartTime:    formatTime(ss),
+					StartTimeStr: ss,
 				}
+				// default to track info if present
+				e.Language, e.Title, e.Titles = parseChapterTitles(v, track.Language)
 
-				m.Entries = append(m.Entries, Entry{
-					StartTime:    st,
-					StartTimeStr: ss,
-					Language:     language,
-					Title:        title,
-				})
+				m.Entries = append(m.Entries, e)
 			}
 			// sort now to make sure endtime last entry is properly set
 			// since track.Extra is a map
@@ -174,19 +235,34 @@ func Inform(f string) (r Info, err error) {
 				return m.Entries[i].StartTime < m.Entries[j].StartTime
 			})
 
-			for i := 0; i < len(m.Entries)-1; i++ {
-				m.Entries[i].EndTime = m.Entries[i+1].StartTime
-				m.Entries[i].EndTimeStr = m.Entries[i+1].StartTimeStr
-			}
-
-			m.Entries[len(m.Entries)-1].EndTime = m.Duration
-			m.Entries[len(m.Entries)-1].EndTimeStr = toFormatTimeStr(m.Duration)
+			setEndTimes(m.Entries, m.Duration)
 
 			r.MenuTracks = append(r.MenuTracks, m)
 		}
 	}
+}
 
-	return
+// parsePIDList parses the PIDs of a program, e.g. "256 (0x100) / 257 (0x101)"
+func parsePIDList(list string) []uint {
+	var pids []uint
+	for _, s := range strings.Split(list, " / ") {
+		if f := strings.Fields(s); len(f) > 0 {
+			if pid, err := strconv.ParseUint(f[0], 10, 16); err == nil {
+				pids = append(pids, uint(pid))
+			}
+		}
+	}
+	return pids
+}
+
+// hasFormat reports if libmediainfo recognized the container or any stream format
+func hasFormat(info informStruct) bool {
+	for _, track := range info.Media.Tracks {
+		if track.Format != "" {
+			return true
+		}
+	}
+	return false
 }
 
 func toUint(s string) uint {
@@ -213,6 +289,41 @@ func toTime(s string) time.Time {
 	return t
 }
 
+var chapterLanguage = regexp.MustCompile(`(?:^| - )([a-z]{2,3}(?:-[A-Za-z0-9]{2,8})*):`)
+
+// parseChapterTitles splits a chapter value into its titles by language.
+// v in format "title", "lang:title" or "lang:title - lang:title"; titles may contain ":".
+// The first language found (or defaultLanguage if none) is returned as the main one.
+func parseChapterTitles(v, defaultLanguage string) (language, title string, titles map[Language]string) {
+	// "xx:" is a language only if xx is an ISO 639 code, titles like "abc: def" are kept whole
+	var idx [][]int
+	for _, match := range chapterLanguage.FindAllStringSubmatchIndex(v, -1) {
+		if isLanguage(v[match[2]:match[3]]) {
+			idx = append(idx, match)
+		}
+	}
+	if len(idx) == 0 || idx[0][0] != 0 {
+		return defaultLanguage, v, map[Language]string{Language(defaultLanguage): v}
+	}
+
+	titles = make(map[Language]string, len(idx))
+	for i, match := range idx {
+		end := len(v)
+		if i < len(idx)-1 {
+			end = idx[i+1][0]
+		}
+		lang := v[match[2]:match[3]]
+		t := v[match[1]:end]
+		if i == 0 {
+			language, title = lang, t
+		}
+		if _, ok := titles[Language(lang)]; !ok {
+			titles[Language(lang)] = t
+		}
+	}
+	return
+}
+
 // s in format _00_00_00_000 => 00:00:00.000
 func formatExtra(s string) string {
 	entries := strings.Split(s[1:], "_")
@@ -236,7 +347,7 @@ func formatTime(s string) float32 {
 
 func toFormatTimeStr(f float32) string {
 	// Rounded to int64 as soon as possible to avoid floating point precision errors
-	t := time.Unix(0, int64(f*1000)*1000*1000) // to nanoseconds.
+	t := time.Unix(0, toMilliseconds(f)*1000*1000) // to nanoseconds.
 	t = t.UTC()
 	return t.Format("15:04:05.000")
 }
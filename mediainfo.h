@@ -14,6 +14,79 @@ void GoSetLocale(void) {
 void GoSetLocale(void) {}
 #endif
 
+// Unix
+#if defined(UNIX) || defined(_UNIX) || defined(__UNIX__)
+static void *GoOpenModule(const char *path) {
+    return dlopen(path, RTLD_LAZY);
+}
+
+static void GoCloseModule(void) {
+    dlclose(MediaInfo_Module);
+}
+
+const char *GoMediaInfoDLL_Error(void) {
+    return dlerror();
+}
+#endif
+
+// windows
+#if defined(WIN32) || defined(WIN64)
+static HMODULE GoOpenModule(const char *path) {
+    wchar_t wpath[32768];
+    if (!MultiByteToWideChar(CP_UTF8, 0, path, -1, wpath, 32768))
+        return NULL;
+    return LoadLibraryW(wpath);
+}
+
+static void GoCloseModule(void) {
+    FreeLibrary(MediaInfo_Module);
+}
+
+const char *GoMediaInfoDLL_Error(void) {
+    return NULL;
+}
+#endif
+
+// GoMediaInfoDLL_LoadFrom is MediaInfoDLL_Load using the library at path (UTF-8) instead of the default locations
+size_t GoMediaInfoDLL_LoadFrom(const char *path) {
+    size_t Errors = 0;
+
+    if (Module_Count > 0) {
+        Module_Count++;
+        return 1;
+    }
+
+    MediaInfo_Module = GoOpenModule(path);
+    if (!MediaInfo_Module)
+        return (size_t) - 1;
+
+    MEDIAINFO_ASSIGN(New, "New")
+    MEDIAINFO_ASSIGN(Delete, "Delete")
+    MEDIAINFO_ASSIGN(Open, "Open")
+    MEDIAINFO_ASSIGN(Open_Buffer_Init, "Open_Buffer_Init")
+    MEDIAINFO_ASSIGN(Open_Buffer_Continue, "Open_Buffer_Continue")
+    MEDIAINFO_ASSIGN(Open_Buffer_Continue_GoTo_Get, "Open_Buffer_Continue_GoTo_Get")
+    MEDIAINFO_ASSIGN(Open_Buffer_Finalize, "Open_Buffer_Finalize")
+    MEDIAINFO_ASSIGN(Open_NextPacket, "Open_NextPacket")
+    MEDIAINFO_ASSIGN(Close, "Close")
+    MEDIAINFO_ASSIGN(Inform, "Inform")
+    MEDIAINFO_ASSIGN(GetI, "GetI")
+    MEDIAINFO_ASSIGN(Get, "Get")
+    MEDIAINFO_ASSIGN(Output_Buffer_Get, "Output_Buffer_Get")
+    MEDIAINFO_ASSIGN(Output_Buffer_GetI, "Output_Buffer_GetI")
+    MEDIAINFO_ASSIGN(Option, "Option")
+    MEDIAINFO_ASSIGN(State_Get, "State_Get")
+    MEDIAINFO_ASSIGN(Count_Get, "Count_Get")
+    if (Errors > 0) {
+        GoCloseModule();
+        MediaInfo_Module = NULL;
+        return (size_t) - 1;
+    }
+
+    Module_Count++;
+    return 1;
+}
+
 
 void *GoMediaInfo_New() {
     return MediaInfo_New();
@@ -42,3 +115,23 @@ const wchar_t *GoMediaInfoOption(void *handle, wchar_t *name, wchar_t *value) {
 const wchar_t *GoMediaInfoInform(void *handle) {
     return MediaInfo_Inform(handle, 0);
 }
+
+size_t GoMediaInfo_Open_Buffer_Init(void *handle, MediaInfo_int64u size, MediaInfo_int64u offset) {
+    return MediaInfo_Open_Buffer_Init(handle, size, offset);
+}
+
+size_t GoMediaInfo_Open_Buffer_Continue(void *handle, MediaInfo_int8u *buffer, size_t size) {
+    return MediaInfo_Open_Buffer_Continue(handle, buffer, size);
+}
+
+MediaInfo_int64u GoMediaInfo_Open_Buffer_Continue_GoTo_Get(void *handle) {
+    return MediaInfo_Open_Buffer_Continue_GoTo_Get(handle);
+}
+
+size_t GoMediaInfo_Open_Buffer_Finalize(void *handle) {
+    return MediaInfo_Open_Buffer_Finalize(handle);
+}
+
+size_t GoMediaInfo_State_Get(void *handle) {
+    return MediaInfo_State_Get(handle);
+}
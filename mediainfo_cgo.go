@@ -3,11 +3,16 @@ package mediainfo
 // #cgo CFLAGS: -DUNICODE -D_UNICODE
 // #include "mediainfo.h"
 // #cgo linux LDFLAGS: -ldl
+// #include <stdlib.h>
 import "C"
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"runtime"
+	"sync"
 	"unsafe"
 )
 
@@ -24,7 +29,11 @@ const (
 	streamMax
 )
 
-var loaded bool
+var (
+	loadMu      sync.Mutex
+	loadCount   int // number of successful Load/LoadFrom calls not yet unloaded
+	handleCount int // number of handles not yet deleted
+)
 
 func init() {
 	C.GoSetLocale()
@@ -40,50 +49,241 @@ type mediaInfo struct {
 	handle unsafe.Pointer
 }
 
-// Load loads the DLL/shared object. If successful returns true.
+// Load loads the DLL/shared object from the default locations. If successful returns true.
+// Load can be called multiple times, each successful call must be paired with an Unload call.
 func Load() bool {
-	r := C.MediaInfoDLL_Load()
-	if r == 1 {
-		loaded = true
-		return true
+	return load("") == nil
+}
+
+// LoadFrom loads the DLL/shared object at path.
+// An error wrapping ErrLibraryNotFound is returned if the library can't be loaded
+// and one wrapping ErrLibraryTooOld if its version is older than MinimumVersion.
+// LoadFrom can be called multiple times, each successful call must be paired with an Unload call.
+func LoadFrom(path string) error {
+	return load(path)
+}
+
+func load(path string) error {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	if loadCount > 0 {
+		loadCount++
+		return nil
+	}
+
+	var r C.size_t
+	if path == "" {
+		r = C.MediaInfoDLL_Load()
+	} else {
+		p := C.CString(path)
+		defer C.free(unsafe.Pointer(p))
+		r = C.GoMediaInfoDLL_LoadFrom(p)
 	}
-	return false
+	if r != 1 {
+		if msg := C.GoMediaInfoDLL_Error(); msg != nil {
+			return fmt.Errorf("%w: %s", ErrLibraryNotFound, C.GoString(msg))
+		}
+		if path != "" {
+			return fmt.Errorf("%w: %s", ErrLibraryNotFound, path)
+		}
+		return ErrLibraryNotFound
+	}
+
+	v, err := libraryVersion()
+	if err == nil && v.Less(MinimumVersion) {
+		err = fmt.Errorf("%w: found %s, need %s or newer", ErrLibraryTooOld, v, MinimumVersion)
+	}
+	if err != nil {
+		C.MediaInfoDLL_UnLoad()
+		return err
+	}
+
+	loadCount = 1
+	return nil
 }
 
-// Unload unloads the DLL/shared object
+// Unload unloads the DLL/shared object once every Load/LoadFrom call has been paired with Unload.
+// Calling Unload when the library is not loaded has no effect.
 func Unload() {
-	C.MediaInfoDLL_UnLoad()
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	if loadCount == 0 {
+		return
+	}
+	loadCount--
+	if loadCount == 0 {
+		C.MediaInfoDLL_UnLoad()
+	}
+}
+
+func isLoaded() bool {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	return loadCount > 0
+}
+
+// LibraryVersion returns the version of the loaded libmediainfo
+func LibraryVersion() (Version, error) {
+	if !isLoaded() {
+		return Version{}, ErrNotLoaded
+	}
+	return libraryVersion()
+}
+
+func libraryVersion() (Version, error) {
+	return parseVersion(option(nil, "Info_Version", ""))
+}
+
+// SetGlobalOption sets a libmediainfo option for every following call (MediaInfo_Option with no handle).
+// It returns the acknowledgement string of libmediainfo, e.g. the version for Info_Version.
+func SetGlobalOption(key, value string) (string, error) {
+	if err := validateOption(key); err != nil {
+		return "", err
+	}
+	if !isLoaded() {
+		return "", ErrNotLoaded
+	}
+
+	response := option(nil, key, value)
+	return response, checkOptionResponse(key, value, response)
+}
+
+func option(handle unsafe.Pointer, key, value string) string {
+	k, v := wideString(key), wideString(value)
+	return toString(C.GoMediaInfoOption(handle, (*C.wchar_t)(unsafe.Pointer(&k[0])), (*C.wchar_t)(unsafe.Pointer(&v[0]))))
 }
 
-// newMediaInfo - constructs new MediaInfo
+// newMediaInfo - constructs new MediaInfo, Close must be called to delete it.
+// Each handle holds a reference to the library, so it is unloaded once every handle is deleted.
 func newMediaInfo() (*mediaInfo, error) {
-	if !loaded {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	if loadCount == 0 {
 		return nil, ErrNotLoaded
 	}
 
-	result := &mediaInfo{handle: C.GoMediaInfo_New()}
+	handle := C.GoMediaInfo_New()
+	if handle == nil {
+		return nil, errors.New("MediaInfo_New failed")
+	}
+	C.MediaInfoDLL_Load() // only increments the reference count of the loaded library
+	handleCount++
+
+	result := &mediaInfo{handle: handle}
+	// safety net for the handles not closed, e.g. an Analyzer neither finalized nor closed
+	runtime.SetFinalizer(result, (*mediaInfo).Close)
 	return result, nil
 }
 
+// liveHandles returns the number of handles not yet deleted
+func liveHandles() int {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	return handleCount
+}
+
 // OpenFile - opens file
 func (mi *mediaInfo) OpenFile(path string) error {
+	// libmediainfo does not report why a file can't be opened, check it first
+	f, err := os.Open(path)
+	if err != nil {
+		return &OpenError{Path: path, Err: unwrapPathError(err)}
+	}
+	f.Close()
+
 	p := wideString(path)
 	s := C.GoMediaInfo_OpenFile(mi.handle, (*C.wchar_t)(unsafe.Pointer(&p[0])))
 	if s == 0 {
-		return fmt.Errorf("MediaInfo can't open file: %s", path)
+		return &OpenError{Path: path, Err: ErrUnsupportedFormat}
 	}
 	return nil
 }
 
-// Close - closes file
+// bufferStatus is the status returned by Open_Buffer_Continue
+type bufferStatus uint
+
+const (
+	bufferAccepted  bufferStatus = 1 << iota // the format is recognized
+	bufferFilled                             // enough data to fill the information
+	bufferUpdated                            // the information changed
+	bufferFinalized                          // no more data is needed
+)
+
+// noSeek is returned by OpenBufferGoTo when libmediainfo continues at the next byte
+const noSeek = -1
+
+// OpenBufferInit starts the analysis of a buffer of size bytes (-1 if unknown) from offset
+func (mi *mediaInfo) OpenBufferInit(size, offset int64) {
+	C.GoMediaInfo_Open_Buffer_Init(mi.handle, C.MediaInfo_int64u(size), C.MediaInfo_int64u(offset))
+}
+
+// OpenBufferContinue sends the next bytes b to libmediainfo
+func (mi *mediaInfo) OpenBufferContinue(b []byte) bufferStatus {
+	if len(b) == 0 {
+		return bufferStatus(C.GoMediaInfo_Open_Buffer_Continue(mi.handle, nil, 0))
+	}
+	return bufferStatus(C.GoMediaInfo_Open_Buffer_Continue(mi.handle, (*C.MediaInfo_int8u)(unsafe.Pointer(&b[0])), C.size_t(len(b))))
+}
+
+// OpenBufferGoTo returns the offset libmediainfo wants to read next, noSeek to continue sequentially
+func (mi *mediaInfo) OpenBufferGoTo() int64 {
+	return int64(C.GoMediaInfo_Open_Buffer_Continue_GoTo_Get(mi.handle))
+}
+
+// OpenBufferFinalize ends the analysis of a buffer
+func (mi *mediaInfo) OpenBufferFinalize() {
+	C.GoMediaInfo_Open_Buffer_Finalize(mi.handle)
+}
+
+// State returns the progress of the analysis, from 0 to 10000
+func (mi *mediaInfo) State() int {
+	return int(C.GoMediaInfo_State_Get(mi.handle))
+}
+
+// SetOptions - sets the options of this handle, must be called before OpenFile
+func (mi *mediaInfo) SetOptions(o *Options) error {
+	for _, kv := range o.values {
+		response := option(mi.handle, kv.key, kv.value)
+		if err := checkOptionResponse(kv.key, kv.value, response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close - closes file and deletes the handle, calling it again has no effect
 func (mi *mediaInfo) Close() {
+	if mi.handle == nil {
+		return
+	}
+	runtime.SetFinalizer(mi, nil)
 	C.GoMediaInfo_Close(mi.handle)
+	C.GoMediaInfo_Delete(mi.handle)
+	mi.handle = nil
+
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	handleCount--
+	C.MediaInfoDLL_UnLoad()
 }
 
 func (mi *mediaInfo) Inform() (informStruct, error) {
 	C.GoMediaInfoOption(mi.handle, (*C.wchar_t)(unsafe.Pointer(&inform[0])), (*C.wchar_t)(unsafe.Pointer(&jsonStr[0])))
-	str := toString(C.GoMediaInfoInform(mi.handle))
+	data := []byte(toString(C.GoMediaInfoInform(mi.handle)))
 	info := informStruct{}
-	err := json.Unmarshal([]byte(str), &info)
-	return info, err
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, newParseError(data, err)
+	}
+	return info, nil
+}
+
+// unwrapPathError returns the cause of a *os.PathError, since OpenError already holds the path
+func unwrapPathError(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
 }
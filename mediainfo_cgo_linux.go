@@ -1,9 +1,6 @@
 package mediainfo
 
-import (
-	"strings"
-	"unsafe"
-)
+import "unsafe"
 
 // #include <wchar.h>
 import "C"
@@ -12,12 +9,11 @@ func wideString(s string) []rune {
 	return append([]rune(s), 0)
 }
 
+// toString copies the NUL-terminated string r, "" if r is nil
 func toString(r *C.wchar_t) string {
-	str := strings.Builder{}
-	ptr := (*rune)(unsafe.Pointer(r))
-	for *ptr != 0 {
-		str.WriteRune(*ptr)
-		ptr = (*rune)(unsafe.Pointer((uintptr(unsafe.Pointer(ptr)) + unsafe.Sizeof(*ptr))))
+	if r == nil {
+		return ""
 	}
-	return str.String()
+	n := int(C.wcslen(r))
+	return string((*[1 << 28]rune)(unsafe.Pointer(r))[:n:n])
 }
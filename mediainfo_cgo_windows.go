@@ -33,12 +33,11 @@ func wideString(s string) []uint16 {
 	return b
 }
 
+// toString copies the NUL-terminated string r, "" if r is nil
 func toString(r *C.wchar_t) string {
-	bytes := make([]uint16, 0)
-	ptr := (*uint16)(unsafe.Pointer(r))
-	for *ptr != 0 {
-		bytes = append(bytes, *ptr)
-		ptr = (*uint16)(unsafe.Pointer((uintptr(unsafe.Pointer(ptr)) + unsafe.Sizeof(bytes[0]))))
+	if r == nil {
+		return ""
 	}
-	return string(utf16.Decode(bytes))
+	n := int(C.wcslen(r))
+	return string(utf16.Decode((*[1 << 29]uint16)(unsafe.Pointer(r))[:n:n]))
 }
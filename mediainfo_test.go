@@ -1,6 +1,7 @@
 package mediainfo
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -129,6 +130,7 @@ func TestMediaInfo(t *testing.T) {
 						EndTimeStr:   "00:01:47.607",
 						Language:     "en",
 						Title:        "Chapter 01",
+						Titles:       map[Language]string{"en": "Chapter 01"},
 					}, {
 						StartTime:    107.607,
 						StartTimeStr: "00:01:47.607",
@@ -136,6 +138,7 @@ func TestMediaInfo(t *testing.T) {
 						EndTimeStr:   "00:14:14.895",
 						Language:     "en",
 						Title:        "Chapter 02",
+						Titles:       map[Language]string{"en": "Chapter 02"},
 					}, {
 						StartTime:    854.895,
 						StartTimeStr: "00:14:14.895",
@@ -143,6 +146,7 @@ func TestMediaInfo(t *testing.T) {
 						EndTimeStr:   "00:22:41.234",
 						Language:     "en",
 						Title:        "Chapter 03",
+						Titles:       map[Language]string{"en": "Chapter 03"},
 					}, {
 						StartTime:    1361.234,
 						StartTimeStr: "00:22:41.234",
@@ -150,6 +154,7 @@ func TestMediaInfo(t *testing.T) {
 						EndTimeStr:   "00:33:14.450",
 						Language:     "en",
 						Title:        "Chapter 04",
+						Titles:       map[Language]string{"en": "Chapter 04"},
 					}, {
 						StartTime:    1994.450,
 						StartTimeStr: "00:33:14.450",
@@ -157,6 +162,7 @@ func TestMediaInfo(t *testing.T) {
 						EndTimeStr:   "00:43:01.370",
 						Language:     "en",
 						Title:        "Chapter 05",
+						Titles:       map[Language]string{"en": "Chapter 05"},
 					}, {
 						StartTime:    2581.370,
 						StartTimeStr: "00:43:01.370",
@@ -164,6 +170,7 @@ func TestMediaInfo(t *testing.T) {
 						EndTimeStr:   "00:54:44.781",
 						Language:     "en",
 						Title:        "Chapter 06",
+						Titles:       map[Language]string{"en": "Chapter 06"},
 					}, {
 						StartTime:    3284.781,
 						StartTimeStr: "00:54:44.781",
@@ -171,6 +178,7 @@ func TestMediaInfo(t *testing.T) {
 						EndTimeStr:   "01:06:51.257",
 						Language:     "en",
 						Title:        "Chapter 07",
+						Titles:       map[Language]string{"en": "Chapter 07"},
 					}, {
 						StartTime:    4011.257,
 						StartTimeStr: "01:06:51.257",
@@ -178,6 +186,7 @@ func TestMediaInfo(t *testing.T) {
 						EndTimeStr:   "01:08:06.355",
 						Language:     "en",
 						Title:        "Chapter 08",
+						Titles:       map[Language]string{"en": "Chapter 08"},
 					}},
 				}},
 			},
@@ -209,6 +218,7 @@ func TestMediaInfo(t *testing.T) {
 					ID:                   1,
 					UniqueID:             "7418062013777177105",
 					Format:               "MPEG Audio",
+					FormatProfile:        "Layer 3",
 					CodecID:              "A_MPEG/L3",
 					Duration:             138.396,
 					BitRate:              0,
@@ -251,6 +261,7 @@ func TestMediaInfo(t *testing.T) {
 					ID:                   1,
 					UniqueID:             "7418062013777177105",
 					Format:               "MPEG Audio",
+					FormatProfile:        "Layer 3",
 					CodecID:              "A_MPEG/L3",
 					Duration:             138.396,
 					BitRate:              0,
@@ -287,6 +298,17 @@ func TestMediaInfo(t *testing.T) {
 	}
 }
 
+func TestLoadFrom(t *testing.T) {
+	err := LoadFrom(filepath.Join("testdata", "libmediainfo_does_not_exist.so"))
+	if !errors.Is(err, ErrLibraryNotFound) {
+		t.Errorf("LoadFrom() error = %v, want %v", err, ErrLibraryNotFound)
+	}
+	if _, err := LibraryVersion(); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("LibraryVersion() error = %v, want %v", err, ErrNotLoaded)
+	}
+	Unload() // must be a no-op
+}
+
 func Test_formatTime(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,288 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// mpegAudioBitRates are the bit rates in kb/s by [MPEG-1][layer-1][index], MPEG-2 and 2.5 share theirs
+var mpegAudioBitRates = [2][3][15]uint{
+	{ // MPEG-2, 2.5
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+	{ // MPEG-1
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+}
+
+// mpegAudioSamplingRates are the sampling rates by version bits and index
+var mpegAudioSamplingRates = [4][3]uint{
+	{11025, 12000, 8000},  // MPEG-2.5
+	{},                    // reserved
+	{22050, 24000, 16000}, // MPEG-2
+	{44100, 48000, 32000}, // MPEG-1
+}
+
+// mpegAudioHeader is a decoded MPEG audio frame header
+type mpegAudioHeader struct {
+	version      byte // 3 MPEG-1, 2 MPEG-2, 0 MPEG-2.5
+	layer        uint
+	bitRate      uint // b/s
+	samplingRate uint
+	channels     uint
+	size         int
+}
+
+// samplesPerFrame returns the number of samples per channel of a frame
+func (h mpegAudioHeader) samplesPerFrame() uint {
+	switch {
+	case h.layer == 1:
+		return 384
+	case h.layer == 3 && h.version != 3:
+		return 576
+	}
+	return 1152
+}
+
+// parseMPEGAudioHeader decodes the 4 bytes frame header b, ok is false if b is not a valid one
+func parseMPEGAudioHeader(b []byte) (h mpegAudioHeader, ok bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return h, false
+	}
+	h.version = (b[1] >> 3) & 3
+	layerBits := (b[1] >> 1) & 3
+	brIndex, srIndex := b[2]>>4, (b[2]>>2)&3
+	if h.version == 1 || layerBits == 0 || brIndex == 0 || brIndex == 15 || srIndex == 3 {
+		return h, false
+	}
+	h.layer = uint(4 - layerBits)
+	mpeg1 := 0
+	if h.version == 3 {
+		mpeg1 = 1
+	}
+	h.bitRate = mpegAudioBitRates[mpeg1][h.layer-1][brIndex] * 1000
+	h.samplingRate = mpegAudioSamplingRates[h.version][srIndex]
+	h.channels = 2
+	if b[3]>>6 == 3 {
+		h.channels = 1
+	}
+
+	padding := uint(b[2]>>1) & 1
+	switch {
+	case h.layer == 1:
+		h.size = int((12*h.bitRate/h.samplingRate + padding) * 4)
+	case h.layer == 3 && h.version != 3:
+		h.size = int(72*h.bitRate/h.samplingRate + padding)
+	default:
+		h.size = int(144*h.bitRate/h.samplingRate + padding)
+	}
+	return h, true
+}
+
+// probeMP3 reads an MPEG audio file (layer 1, 2 or 3) with optional ID3v2 and ID3v1 tags.
+// The duration is read from the Xing/Info or VBRI header, or estimated from the first frame bit rate.
+func probeMP3(r io.ReaderAt, size int64) (Info, error) {
+	start := id3v2Size(r)
+	tagsV1, tagV1Size := readID3v1(r, size)
+	end := size - tagV1Size
+
+	// the first frame, confirmed by the next one, in the first 64KiB after the tags
+	buf, err := readAt(r, start, 64<<10)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return Info{}, err
+	}
+	var h mpegAudioHeader
+	offset := -1
+	for i := 0; i+4 <= len(buf); i++ {
+		first, ok := parseMPEGAudioHeader(buf[i:])
+		if !ok {
+			continue
+		}
+		next := i + first.size
+		if next+4 > len(buf) {
+			if start+int64(next) == end {
+				h, offset = first, i
+				break
+			}
+			continue
+		}
+		if second, ok := parseMPEGAudioHeader(buf[next:]); ok && second.version == first.version && second.layer == first.layer {
+			h, offset = first, i
+			break
+		}
+	}
+	if offset < 0 {
+		return Info{}, errNotAudioFormat
+	}
+
+	tags, err := readID3v2(r, size)
+	if err != nil {
+		return Info{}, err
+	}
+	tags = mergeTags(tags, tagsV1)
+
+	a := Audio{
+		Format:          "MPEG Audio",
+		FormatProfile:   "Layer " + string(rune('0'+h.layer)),
+		Channels:        h.channels,
+		SamplingRate:    h.samplingRate,
+		SamplesPerFrame: h.samplesPerFrame(),
+		CompressionMode: "Lossy",
+		BitRate:         float32(h.bitRate),
+	}
+	audioSize := end - start - int64(offset)
+	frame := buf[offset:]
+	frames, library := mpegAudioVBRHeader(h, frame)
+	if frames > 0 {
+		a.FrameCount = frames
+		a.SamplingCount = frames * a.SamplesPerFrame
+		a.Duration = float32(a.SamplingCount) / float32(a.SamplingRate)
+		a.BitRate = float32(float64(audioSize) * 8 / float64(a.Duration))
+	} else {
+		a.Duration = float32(float64(audioSize) * 8 / float64(h.bitRate))
+		a.FrameCount = uint(audioSize / int64(h.size))
+		a.SamplingCount = uint(float64(a.Duration) * float64(a.SamplingRate))
+	}
+	a.StreamSize = uint(audioSize)
+
+	info := newAudioInfo("MPEG Audio", size, a, tags)
+	info.General.EncodedLibrary = library
+	return info, nil
+}
+
+// mpegAudioVBRHeader returns the frame count and encoder of the Xing/Info or VBRI header of the first frame
+func mpegAudioVBRHeader(h mpegAudioHeader, frame []byte) (frames uint, library string) {
+	// Xing follows the side information
+	side := 32
+	switch {
+	case h.version == 3 && h.channels == 1:
+		side = 17
+	case h.version != 3 && h.channels == 1:
+		side = 9
+	case h.version != 3:
+		side = 17
+	}
+	if x := 4 + side; len(frame) >= x+8 && (string(frame[x:x+4]) == "Xing" || string(frame[x:x+4]) == "Info") {
+		flags := binary.BigEndian.Uint32(frame[x+4:])
+		p := x + 8
+		if flags&1 != 0 && len(frame) >= p+4 {
+			frames = uint(binary.BigEndian.Uint32(frame[p:]))
+		}
+		// frames, bytes, TOC and quality precede the LAME tag
+		for bit, n := range []int{4, 4, 100, 4} {
+			if flags&(1<<uint(bit)) != 0 {
+				p += n
+			}
+		}
+		if len(frame) >= p+9 && (strings.HasPrefix(string(frame[p:]), "LAME") || strings.HasPrefix(string(frame[p:]), "Lavc")) {
+			library = strings.TrimRight(string(frame[p:p+9]), "\x00 ")
+		}
+		return frames, library
+	}
+	if x := 4 + 32; len(frame) >= x+18 && string(frame[x:x+4]) == "VBRI" {
+		return uint(binary.BigEndian.Uint32(frame[x+14:])), ""
+	}
+	return 0, ""
+}
+
+// adtsSamplingRates are the AAC sampling rates by index
+var adtsSamplingRates = []uint{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// adtsProfiles are the AAC object types of the ADTS profile field
+var adtsProfiles = []string{"Main", "LC", "SSR", "LTP"}
+
+// adtsHeader is a decoded ADTS frame header
+type adtsHeader struct {
+	profile      string
+	samplingRate uint
+	channels     uint
+	size         int
+	blocks       uint
+}
+
+// parseADTSHeader decodes the 7 bytes ADTS header b, ok is false if b is not a valid one
+func parseADTSHeader(b []byte) (h adtsHeader, ok bool) {
+	if len(b) < 7 || b[0] != 0xFF || b[1]&0xF6 != 0xF0 {
+		return h, false
+	}
+	srIndex := int(b[2]>>2) & 0xF
+	if srIndex >= len(adtsSamplingRates) {
+		return h, false
+	}
+	h.profile = adtsProfiles[b[2]>>6]
+	h.samplingRate = adtsSamplingRates[srIndex]
+	h.channels = uint(b[2]&1)<<2 | uint(b[3]>>6)
+	if h.channels == 7 {
+		h.channels = 8
+	}
+	h.size = int(b[3]&3)<<11 | int(b[4])<<3 | int(b[5]>>5)
+	h.blocks = uint(b[6]&3) + 1
+	return h, h.size >= 7
+}
+
+// probeADTS reads an AAC ADTS file with an optional ID3v2 tag.
+// ADTS has no duration header, so all frame headers are read.
+func probeADTS(r io.ReaderAt, size int64) (Info, error) {
+	start := id3v2Size(r)
+	tagsV1, tagV1Size := readID3v1(r, size)
+	end := size - tagV1Size
+
+	b, err := readAt(r, start, 7)
+	if err != nil {
+		return Info{}, errNotAudioFormat
+	}
+	first, ok := parseADTSHeader(b)
+	if !ok {
+		return Info{}, errNotAudioFormat
+	}
+	if next, err := readAt(r, start+int64(first.size), 7); err == nil {
+		if _, ok := parseADTSHeader(next); !ok {
+			return Info{}, errNotAudioFormat
+		}
+	} else if start+int64(first.size) != end {
+		return Info{}, errNotAudioFormat
+	}
+
+	var frames, samples uint
+	for off := start; off+7 <= end; {
+		b, err := readAt(r, off, 7)
+		if err != nil {
+			return Info{}, err
+		}
+		h, ok := parseADTSHeader(b)
+		if !ok {
+			break
+		}
+		frames++
+		samples += 1024 * h.blocks
+		off += int64(h.size)
+	}
+
+	tags, err := readID3v2(r, size)
+	if err != nil {
+		return Info{}, err
+	}
+	tags = mergeTags(tags, tagsV1)
+
+	a := Audio{
+		Format:                   "AAC",
+		FormatAdditionalFeatures: first.profile,
+		Channels:                 first.channels,
+		SamplingRate:             first.samplingRate,
+		SamplesPerFrame:          1024,
+		SamplingCount:            samples,
+		FrameCount:               frames,
+		CompressionMode:          "Lossy",
+		StreamSize:               uint(end - start),
+	}
+	a.Duration = float32(samples) / float32(first.samplingRate)
+	if a.Duration > 0 {
+		a.BitRate = float32(float64(a.StreamSize) * 8 / float64(a.Duration))
+	}
+	return newAudioInfo("ADTS", size, a, tags), nil
+}
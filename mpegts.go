@@ -0,0 +1,552 @@
+package mediainfo
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MPEG-TS constants
+const (
+	tsPacketSize   = 188
+	m2tsPacketSize = 192 // Blu-ray: 4 bytes timestamp before each packet
+	tsSyncByte     = 0x47
+	tsPIDPAT       = 0x0000
+	tsPIDSDT       = 0x0011
+	tsTablePAT     = 0x00
+	tsTablePMT     = 0x02
+	tsTableSDT     = 0x42
+	tsPCRClock     = 27000000
+	tsPCRWrap      = (1 << 33) * 300
+
+	tsTableScanLimit = 8 << 20 // bytes read at the start of the file to find the tables
+	tsPCRScanLength  = 2 << 20 // bytes read at the end of the file to find the last PCR
+	tsSDTWait        = 2 << 20 // bytes read after the PMTs before giving up on the SDT (none in BDAV, ATSC)
+)
+
+// errNotTransportStream is returned when data is not a MPEG transport stream
+var errNotTransportStream = errors.New("not a MPEG transport stream")
+
+// tsStreamTypes maps PMT stream types to the track kind and libmediainfo format
+var tsStreamTypes = map[byte]struct{ kind, format string }{
+	0x01: {"Video", "MPEG Video"},
+	0x02: {"Video", "MPEG Video"},
+	0x03: {"Audio", "MPEG Audio"},
+	0x04: {"Audio", "MPEG Audio"},
+	0x0F: {"Audio", "AAC"},
+	0x10: {"Video", "MPEG-4 Visual"},
+	0x11: {"Audio", "AAC"},
+	0x1B: {"Video", "AVC"},
+	0x24: {"Video", "HEVC"},
+	0x33: {"Video", "VVC"},
+	0x80: {"Audio", "PCM"},
+	0x81: {"Audio", "AC-3"},
+	0x82: {"Audio", "DTS"},
+	0x83: {"Audio", "MLP FBA"},
+	0x84: {"Audio", "E-AC-3"},
+	0x85: {"Audio", "DTS"},
+	0x86: {"Audio", "DTS"},
+	0x87: {"Audio", "E-AC-3"},
+	0x90: {"Text", "PGS"},
+	0x92: {"Text", "Blu-ray Text"},
+	0xA1: {"Audio", "E-AC-3"},
+	0xA2: {"Audio", "DTS"},
+	0xEA: {"Video", "VC-1"},
+}
+
+// tsDescriptorFormats maps the descriptors identifying private data streams (stream type 0x06)
+// to the track kind and libmediainfo format
+var tsDescriptorFormats = map[byte]struct{ kind, format string }{
+	0x56: {"Text", "Teletext"},
+	0x59: {"Text", "DVB Subtitle"},
+	0x6A: {"Audio", "AC-3"},
+	0x7A: {"Audio", "E-AC-3"},
+	0x7B: {"Audio", "DTS"},
+}
+
+// tsStream represents an elementary stream of a PMT
+type tsStream struct {
+	pid        uint
+	streamType byte
+	kind       string
+	format     string
+	features   string
+	language   string
+}
+
+// tsProgram represents a program being parsed
+type tsProgram struct {
+	Program
+	streams []tsStream
+	parsed  bool
+}
+
+// tsParser reads the PSI tables of a transport stream
+type tsParser struct {
+	programs []*tsProgram
+	pmtPIDs  map[uint]*tsProgram
+	sections map[uint][]byte // partial sections by PID
+	patDone  bool
+	sdtDone  bool
+	noSDT    bool // no SDT within tsSDTWait bytes after the PMTs
+}
+
+// InformTS analyzes a MPEG transport stream (.ts, .m2ts) without libmediainfo:
+// programs and services are read from the PAT, PMT and SDT tables and the duration is estimated from the PCR.
+// Only container level information is available, e.g. video size and bit rates are unknown.
+func InformTS(path string) (Info, error) {
+	path, _ = filepath.Abs(path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, &OpenError{Path: path, Err: unwrapPathError(err)}
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return Info{}, &OpenError{Path: path, Err: unwrapPathError(err)}
+	}
+
+	info, err := readTransportStream(f, fi.Size())
+	if err == errNotTransportStream {
+		return Info{}, &OpenError{Path: path, Err: ErrUnsupportedFormat}
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	info.General.CompleteName = path
+	info.General.FileExtension = strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	return info, nil
+}
+
+// tsPacketLayout returns the offset of the first packet and the packet size of r
+func tsPacketLayout(r io.ReaderAt, size int64) (start, packetSize int64, err error) {
+	buf := make([]byte, 3*m2tsPacketSize+4)
+	n, err := r.ReadAt(buf, 0)
+	if err != nil && err != io.EOF {
+		return 0, 0, err
+	}
+	buf = buf[:n]
+
+	for _, layout := range [][2]int64{{0, tsPacketSize}, {4, m2tsPacketSize}} {
+		start, packetSize := layout[0], layout[1]
+		ok := int64(len(buf)) >= start+1
+		for i := int64(0); ok && i < 3 && start+i*packetSize < int64(len(buf)); i++ {
+			ok = buf[start+i*packetSize] == tsSyncByte
+		}
+		if ok && size >= start+packetSize {
+			return start, packetSize, nil
+		}
+	}
+	return 0, 0, errNotTransportStream
+}
+
+// readTransportStream reads the programs, tracks and duration of a transport stream
+func readTransportStream(r io.ReaderAt, size int64) (Info, error) {
+	start, packetSize, err := tsPacketLayout(r, size)
+	if err != nil {
+		return Info{}, err
+	}
+
+	p := &tsParser{pmtPIDs: map[uint]*tsProgram{}, sections: map[uint][]byte{}}
+	var firstPCR int64 = -1
+	var pcrPID uint
+	pcrPIDKnown := false
+	var sdtWait int64 // bytes read since the PMTs were parsed, waiting for the SDT
+
+	limit := size
+	if limit > tsTableScanLimit {
+		limit = tsTableScanLimit
+	}
+	err = tsPackets(r, start, limit, packetSize, func(pkt []byte) bool {
+		pid := tsPID(pkt)
+		if pcrPIDKnown && pid == pcrPID && firstPCR < 0 {
+			firstPCR = tsPCR(pkt)
+		}
+		if pid == tsPIDPAT || pid == tsPIDSDT || p.pmtPIDs[pid] != nil {
+			p.packet(pid, pkt)
+		}
+		if !pcrPIDKnown && len(p.programs) > 0 && p.programs[0].parsed {
+			pcrPID, pcrPIDKnown = p.programs[0].PCRPID, true
+		}
+		if !p.sdtDone && p.pmtsDone() {
+			sdtWait += packetSize
+			p.noSDT = sdtWait >= tsSDTWait
+		}
+		return !(p.done() && (!pcrPIDKnown || firstPCR >= 0))
+	})
+	if err != nil {
+		return Info{}, err
+	}
+	if !p.patDone {
+		return Info{}, errNotTransportStream
+	}
+
+	info := Info{}
+	info.General.Format = "MPEG-TS"
+	if packetSize == m2tsPacketSize {
+		info.General.Format = "BDAV"
+	}
+	info.General.FileSize = uint(size)
+
+	if pcrPIDKnown && firstPCR >= 0 {
+		tail := size - tsPCRScanLength
+		if tail < start {
+			tail = start
+		}
+		tail = start + (tail-start)/packetSize*packetSize
+		lastPCR := int64(-1)
+		err = tsPackets(r, tail, size, packetSize, func(pkt []byte) bool {
+			if tsPID(pkt) == pcrPID {
+				if pcr := tsPCR(pkt); pcr >= 0 {
+					lastPCR = pcr
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return Info{}, err
+		}
+		if lastPCR >= 0 {
+			d := lastPCR - firstPCR
+			if d < 0 {
+				d += tsPCRWrap
+			}
+			info.General.Duration = float32(float64(d) / tsPCRClock)
+		}
+	}
+	if info.General.Duration > 0 {
+		info.General.OverallBitRate = float32(float64(size) * 8 / float64(info.General.Duration))
+	}
+
+	p.setTracks(&info)
+	return info, nil
+}
+
+// tsPackets calls fn for each packet between start and end, until fn returns false
+func tsPackets(r io.ReaderAt, start, end, packetSize int64, fn func(pkt []byte) bool) error {
+	const packetsPerRead = 512
+	buf := make([]byte, packetsPerRead*packetSize)
+	for off := start; off+tsPacketSize <= end; {
+		b := buf
+		if end-off < int64(len(b)) {
+			b = b[:end-off]
+		}
+		n, err := r.ReadAt(b, off)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		for i := 0; i+tsPacketSize <= n; i += int(packetSize) {
+			pkt := b[i : i+tsPacketSize]
+			if pkt[0] != tsSyncByte {
+				// lost sync, skip the packet
+				continue
+			}
+			if !fn(pkt) {
+				return nil
+			}
+		}
+		off += int64(n) / packetSize * packetSize
+		if err == io.EOF || int64(n) < packetSize || n < len(b) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// tsPID returns the PID of packet pkt
+func tsPID(pkt []byte) uint {
+	return uint(pkt[1]&0x1F)<<8 | uint(pkt[2])
+}
+
+// tsPayload returns the payload of packet pkt and whether it starts a PES packet or a section
+func tsPayload(pkt []byte) (payload []byte, unitStart bool) {
+	unitStart = pkt[1]&0x40 != 0
+	afc := pkt[3] >> 4 & 0x3
+	if afc&0x1 == 0 {
+		return nil, unitStart
+	}
+	off := 4
+	if afc&0x2 != 0 {
+		off += 1 + int(pkt[4])
+	}
+	if off >= len(pkt) {
+		return nil, unitStart
+	}
+	return pkt[off:], unitStart
+}
+
+// tsPCR returns the PCR of packet pkt in 27 MHz units, -1 if it has none
+func tsPCR(pkt []byte) int64 {
+	if pkt[3]&0x20 == 0 || pkt[4] < 7 || pkt[5]&0x10 == 0 {
+		return -1
+	}
+	b := pkt[6:12]
+	base := int64(b[0])<<25 | int64(b[1])<<17 | int64(b[2])<<9 | int64(b[3])<<1 | int64(b[4])>>7
+	ext := int64(b[4]&0x01)<<8 | int64(b[5])
+	return base*300 + ext
+}
+
+// done reports whether every table was parsed, or given up on for the SDT
+func (p *tsParser) done() bool {
+	return p.pmtsDone() && (p.sdtDone || p.noSDT)
+}
+
+// pmtsDone reports whether the PAT and the PMTs of all its programs were parsed
+func (p *tsParser) pmtsDone() bool {
+	if !p.patDone {
+		return false
+	}
+	for _, prog := range p.programs {
+		if !prog.parsed {
+			return false
+		}
+	}
+	return true
+}
+
+// packet assembles the sections of a PSI packet and parses the complete ones
+func (p *tsParser) packet(pid uint, pkt []byte) {
+	payload, unitStart := tsPayload(pkt)
+	if len(payload) == 0 {
+		return
+	}
+	buf := p.sections[pid]
+	if unitStart {
+		pointer := int(payload[0])
+		if 1+pointer > len(payload) {
+			return
+		}
+		if buf != nil {
+			buf = append(buf, payload[1:1+pointer]...)
+			p.section(pid, buf)
+		}
+		buf = append([]byte(nil), payload[1+pointer:]...)
+	} else if buf != nil {
+		buf = append(buf, payload...)
+	}
+
+	// a packet may contain several sections
+	for len(buf) >= 3 && buf[0] != 0xFF {
+		length := 3 + (int(buf[1]&0x0F)<<8 | int(buf[2]))
+		if len(buf) < length {
+			break
+		}
+		p.section(pid, buf[:length])
+		buf = buf[length:]
+	}
+	if len(buf) == 0 || buf[0] == 0xFF {
+		buf = nil
+	}
+	p.sections[pid] = buf
+}
+
+// section parses a complete PSI section, the CRC is not checked
+func (p *tsParser) section(pid uint, s []byte) {
+	if len(s) < 12 {
+		return
+	}
+	length := 3 + (int(s[1]&0x0F)<<8 | int(s[2]))
+	if length > len(s) || length < 12 {
+		return
+	}
+	body := s[8 : length-4] // after the common header, before the CRC
+
+	switch {
+	case pid == tsPIDPAT && s[0] == tsTablePAT && !p.patDone:
+		p.parsePAT(body)
+	case pid == tsPIDSDT && s[0] == tsTableSDT && p.patDone && !p.sdtDone && len(body) >= 3:
+		p.parseSDT(body[3:])
+	case s[0] == tsTablePMT:
+		if prog := p.pmtPIDs[pid]; prog != nil && !prog.parsed {
+			prog.parsePMT(body)
+		}
+	}
+}
+
+func (p *tsParser) parsePAT(b []byte) {
+	for ; len(b) >= 4; b = b[4:] {
+		number := uint(b[0])<<8 | uint(b[1])
+		pid := uint(b[2]&0x1F)<<8 | uint(b[3])
+		if number == 0 {
+			// network PID
+			continue
+		}
+		prog := &tsProgram{Program: Program{Number: number, PMTPID: pid}}
+		p.programs = append(p.programs, prog)
+		p.pmtPIDs[pid] = prog
+	}
+	p.patDone = true
+}
+
+func (prog *tsProgram) parsePMT(b []byte) {
+	if len(b) < 4 {
+		return
+	}
+	prog.PCRPID = uint(b[0]&0x1F)<<8 | uint(b[1])
+	infoLength := int(b[2]&0x0F)<<8 | int(b[3])
+	if 4+infoLength > len(b) {
+		return
+	}
+	for b = b[4+infoLength:]; len(b) >= 5; {
+		s := tsStream{streamType: b[0], pid: uint(b[1]&0x1F)<<8 | uint(b[2])}
+		esLength := int(b[3]&0x0F)<<8 | int(b[4])
+		if 5+esLength > len(b) {
+			break
+		}
+		t, known := tsStreamTypes[s.streamType]
+		s.kind, s.format = t.kind, t.format
+		tsDescriptors(b[5:5+esLength], func(tag byte, d []byte) {
+			switch tag {
+			case 0x0A, 0x59: // ISO 639 language, DVB subtitling
+				if s.language == "" && len(d) >= 3 {
+					s.language = strings.TrimSpace(string(d[:3]))
+				}
+			case 0x05: // registration
+				if !known && len(d) >= 4 && string(d[:4]) == "Opus" {
+					s.kind, s.format = "Audio", "Opus"
+				}
+			}
+			if f, ok := tsDescriptorFormats[tag]; ok && s.streamType == 0x06 && s.kind == "" {
+				s.kind, s.format = f.kind, f.format
+			}
+		})
+		if s.streamType == 0x86 {
+			s.features = "XLL" // DTS-HD Master Audio
+		}
+
+		prog.PIDs = append(prog.PIDs, s.pid)
+		prog.streams = append(prog.streams, s)
+		b = b[5+esLength:]
+	}
+	prog.parsed = true
+}
+
+func (p *tsParser) parseSDT(b []byte) {
+	for len(b) >= 5 {
+		id := uint(b[0])<<8 | uint(b[1])
+		length := int(b[3]&0x0F)<<8 | int(b[4])
+		if 5+length > len(b) {
+			break
+		}
+		var prog *tsProgram
+		for _, candidate := range p.programs {
+			if candidate.Number == id {
+				prog = candidate
+			}
+		}
+		tsDescriptors(b[5:5+length], func(tag byte, d []byte) {
+			if tag != 0x48 || prog == nil || len(d) < 2 {
+				return
+			}
+			// service descriptor: type, provider, name
+			providerLength := int(d[1])
+			if 2+providerLength >= len(d) {
+				return
+			}
+			prog.ServiceProvider = dvbString(d[2 : 2+providerLength])
+			d = d[2+providerLength:]
+			if 1+int(d[0]) <= len(d) {
+				prog.ServiceName = dvbString(d[1 : 1+int(d[0])])
+			}
+		})
+		b = b[5+length:]
+	}
+	p.sdtDone = true
+}
+
+// tsDescriptors calls fn for each descriptor in b
+func tsDescriptors(b []byte, fn func(tag byte, data []byte)) {
+	for len(b) >= 2 {
+		length := int(b[1])
+		if 2+length > len(b) {
+			return
+		}
+		fn(b[0], b[2:2+length])
+		b = b[2+length:]
+	}
+}
+
+// dvbString decodes a DVB text, the character table selector (first byte below 0x20) is skipped
+// and the rest is read as Latin-1, UTF-8 when selected by 0x15
+func dvbString(b []byte) string {
+	utf8 := false
+	if len(b) > 0 && b[0] < 0x20 {
+		utf8 = b[0] == 0x15
+		switch b[0] {
+		case 0x10:
+			if len(b) < 3 {
+				return ""
+			}
+			b = b[3:]
+		case 0x1F:
+			if len(b) < 2 {
+				return ""
+			}
+			b = b[2:]
+		default:
+			b = b[1:]
+		}
+	}
+	if utf8 {
+		return string(b)
+	}
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+// setTracks adds the programs and tracks found by p to info
+func (p *tsParser) setTracks(info *Info) {
+	order := uint(0)
+	seen := map[uint]bool{}
+	for _, prog := range p.programs {
+		info.Programs = append(info.Programs, prog.Program)
+		for _, s := range prog.streams {
+			if seen[s.pid] {
+				// shared by several programs
+				continue
+			}
+			seen[s.pid] = true
+
+			switch s.kind {
+			case "Video":
+				info.VideoTracks = append(info.VideoTracks, Video{
+					StreamOrder: order,
+					ID:          s.pid,
+					Format:      s.format,
+					Duration:    info.General.Duration,
+				})
+			case "Audio":
+				info.AudioTracks = append(info.AudioTracks, Audio{
+					StreamOrder:              order,
+					ID:                       s.pid,
+					Format:                   s.format,
+					FormatAdditionalFeatures: s.features,
+					Duration:                 info.General.Duration,
+					Language:                 s.language,
+				})
+			case "Text":
+				info.TextTracks = append(info.TextTracks, Text{
+					Order:       uint(len(info.TextTracks)),
+					StreamOrder: order,
+					ID:          s.pid,
+					Format:      s.format,
+					Language:    s.language,
+				})
+			}
+			order++
+		}
+	}
+	info.General.VideoCount = uint(len(info.VideoTracks))
+	info.General.AudioCount = uint(len(info.AudioTracks))
+	info.General.TextCount = uint(len(info.TextTracks))
+}
@@ -0,0 +1,208 @@
+package mediainfo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// tsSection returns a PSI section with table id, extension and body (the CRC is not computed)
+func tsSection(tableID byte, extension uint16, body []byte) []byte {
+	length := 5 + len(body) + 4
+	s := []byte{tableID, 0xB0 | byte(length>>8), byte(length), byte(extension >> 8), byte(extension), 0xC1, 0, 0}
+	s = append(s, body...)
+	return append(s, 0, 0, 0, 0)
+}
+
+// tsPSIPacket returns a packet of pid carrying section
+func tsPSIPacket(pid uint16, section []byte) []byte {
+	pkt := []byte{tsSyncByte, 0x40 | byte(pid>>8), byte(pid), 0x10, 0}
+	pkt = append(pkt, section...)
+	for len(pkt) < tsPacketSize {
+		pkt = append(pkt, 0xFF)
+	}
+	return pkt
+}
+
+// tsPCRPacket returns a packet of pid with an adaptation field holding pcr (27 MHz)
+func tsPCRPacket(pid uint16, pcr int64) []byte {
+	base, ext := pcr/300, pcr%300
+	pkt := []byte{tsSyncByte, byte(pid >> 8), byte(pid), 0x30, 7, 0x10,
+		byte(base >> 25), byte(base >> 17), byte(base >> 9), byte(base >> 1), byte(base<<7) | 0x7E | byte(ext>>8), byte(ext)}
+	for len(pkt) < tsPacketSize {
+		pkt = append(pkt, 0xFF)
+	}
+	return pkt
+}
+
+// testTransportStream returns a stream with one program: H.264 video, E-AC-3 audio and DVB subtitles
+func testTransportStream(prefix bool) []byte {
+	pat := tsSection(tsTablePAT, 1, []byte{0, 0, 0xE0, 0x10, 0, 1, 0xF0, 0x00})
+	pmt := tsSection(tsTablePMT, 1, []byte{
+		0xE1, 0x00, 0xF0, 0x00, // PCR PID 0x100, no program descriptors
+		0x1B, 0xE1, 0x00, 0xF0, 0x00, // H.264
+		0x06, 0xE1, 0x01, 0xF0, 0x0B, 0x7A, 0x01, 0x00, 0x0A, 0x04, 'f', 'r', 'a', 0, 0x7A, 0x00, // E-AC-3, French
+		0x06, 0xE1, 0x02, 0xF0, 0x0A, 0x59, 0x08, 'e', 'n', 'g', 0x10, 0, 1, 0, 1, // DVB subtitles, English
+	})
+	sdt := tsSection(tsTableSDT, 1, []byte{
+		0, 1, 0xFF, // original network ID
+		0, 1, 0xFC, 0x80, 0x10, // service 1, descriptors length 16
+		0x48, 0x0E, 0x01, 0x04, 'T', 'e', 's', 't', 0x07, 0x15, 'C', 'h', 'a', 'n', ' ', '1',
+	})
+
+	packets := [][]byte{
+		tsPSIPacket(tsPIDPAT, pat),
+		tsPSIPacket(tsPIDSDT, sdt),
+		tsPSIPacket(0x1000, pmt),
+		tsPCRPacket(0x100, 27000000),
+		tsPCRPacket(0x101, 0),
+		tsPCRPacket(0x100, 27000000+15*tsPCRClock/2),
+	}
+
+	var b bytes.Buffer
+	for _, pkt := range packets {
+		if prefix {
+			b.Write([]byte{0, 0, 0, 0})
+		}
+		b.Write(pkt)
+	}
+	return b.Bytes()
+}
+
+func Test_readTransportStream(t *testing.T) {
+	for _, m2ts := range []bool{false, true} {
+		data := testTransportStream(m2ts)
+		info, err := readTransportStream(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("readTransportStream() error = %v", err)
+		}
+
+		format := "MPEG-TS"
+		if m2ts {
+			format = "BDAV"
+		}
+		want := Info{
+			General: General{
+				VideoCount:     1,
+				AudioCount:     1,
+				TextCount:      1,
+				Format:         format,
+				FileSize:       uint(len(data)),
+				Duration:       7.5,
+				OverallBitRate: float32(len(data)) * 8 / 7.5,
+			},
+			VideoTracks: []Video{{StreamOrder: 0, ID: 0x100, Format: "AVC", Duration: 7.5}},
+			AudioTracks: []Audio{{StreamOrder: 1, ID: 0x101, Format: "E-AC-3", Duration: 7.5, Language: "fra"}},
+			TextTracks:  []Text{{StreamOrder: 2, ID: 0x102, Format: "DVB Subtitle", Language: "eng"}},
+			Programs: []Program{{
+				Number:          1,
+				PMTPID:          0x1000,
+				PCRPID:          0x100,
+				ServiceName:     "Chan 1",
+				ServiceProvider: "Test",
+				PIDs:            []uint{0x100, 0x101, 0x102},
+			}},
+		}
+		if !reflect.DeepEqual(info, want) {
+			t.Errorf("readTransportStream(m2ts=%v)\nGot \n%+v\nWant\n%+v", m2ts, info, want)
+		}
+		if c := info.AudioTracks[0].Codec(); c != AudioCodecEAC3 {
+			t.Errorf("Codec() = %v, want E-AC-3", c)
+		}
+	}
+}
+
+func Test_readTransportStream_truncatedSDT(t *testing.T) {
+	pat := tsSection(tsTablePAT, 1, []byte{0, 0, 0xE0, 0x10, 0, 1, 0xF0, 0x00})
+	pmt := tsSection(tsTablePMT, 1, []byte{0xE1, 0x00, 0xF0, 0x00, 0x1B, 0xE1, 0x00, 0xF0, 0x00})
+	for n := 0; n < 3; n++ {
+		// 12 to 14 bytes sections, without the original network ID
+		sdt := tsSection(tsTableSDT, 1, make([]byte, n))
+		var b bytes.Buffer
+		for _, pkt := range [][]byte{tsPSIPacket(tsPIDPAT, pat), tsPSIPacket(tsPIDSDT, sdt), tsPSIPacket(0x1000, pmt)} {
+			b.Write(pkt)
+		}
+		info, err := readTransportStream(bytes.NewReader(b.Bytes()), int64(b.Len()))
+		if err != nil {
+			t.Fatalf("readTransportStream() error = %v", err)
+		}
+		if len(info.Programs) != 1 || info.Programs[0].ServiceName != "" {
+			t.Errorf("readTransportStream() Programs = %+v", info.Programs)
+		}
+	}
+}
+
+// countingReaderAt counts the bytes read from r
+type countingReaderAt struct {
+	r    io.ReaderAt
+	read int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read += int64(n)
+	return n, err
+}
+
+func Test_readTransportStream_noSDT(t *testing.T) {
+	pat := tsSection(tsTablePAT, 1, []byte{0, 0, 0xE0, 0x10, 0, 1, 0xF0, 0x00})
+	pmt := tsSection(tsTablePMT, 1, []byte{0xE1, 0x00, 0xF0, 0x00, 0x1B, 0xE1, 0x00, 0xF0, 0x00})
+	var b bytes.Buffer
+	for _, pkt := range [][]byte{tsPSIPacket(tsPIDPAT, pat), tsPSIPacket(0x1000, pmt), tsPCRPacket(0x100, 0)} {
+		b.Write(pkt)
+	}
+	// null packets up to twice the table scan limit
+	null := tsPCRPacket(0x1FFF, 0)
+	for b.Len() < 2*tsTableScanLimit {
+		b.Write(null)
+	}
+
+	r := &countingReaderAt{r: bytes.NewReader(b.Bytes())}
+	info, err := readTransportStream(r, int64(b.Len()))
+	if err != nil {
+		t.Fatalf("readTransportStream() error = %v", err)
+	}
+	if len(info.Programs) != 1 || len(info.VideoTracks) != 1 {
+		t.Errorf("readTransportStream() = %+v", info)
+	}
+	// the tables, tsSDTWait bytes waiting for the SDT and the end of the file for the last PCR
+	if max := int64(tsSDTWait + tsPCRScanLength + 512*tsPacketSize); r.read > max {
+		t.Errorf("readTransportStream() read %d bytes, want at most %d", r.read, max)
+	}
+}
+
+func TestInformTS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := filepath.Join(dir, "capture.TS")
+	ioutil.WriteFile(ts, testTransportStream(false), 0644)
+	info, err := InformTS(ts)
+	if err != nil {
+		t.Fatalf("InformTS() error = %v", err)
+	}
+	if info.General.CompleteName != ts || info.General.FileExtension != "ts" || len(info.Programs) != 1 {
+		t.Errorf("InformTS() = %+v", info)
+	}
+
+	other := filepath.Join(dir, "other.ts")
+	ioutil.WriteFile(other, bytes.Repeat([]byte("not a transport stream"), 50), 0644)
+	if _, err := InformTS(other); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("InformTS() error = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func Test_parsePIDList(t *testing.T) {
+	got := parsePIDList("256 (0x100) / 257 (0x101) / 4352")
+	if want := []uint{256, 257, 4352}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePIDList() = %v, want %v", got, want)
+	}
+}
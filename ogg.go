@@ -0,0 +1,171 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	// oggPageHeaderSize is the size of a page header without its segment table
+	oggPageHeaderSize = 27
+	// oggTailScan is the size read at the end of the file to find the last granule position
+	oggTailScan = 64 << 10
+	// oggMaxHeaderPackets is the size limit of the identification and comment packets
+	oggMaxHeaderPackets = 1 << 20
+)
+
+// oggPage is an Ogg page header
+type oggPage struct {
+	granule  int64
+	serial   uint32
+	segments []byte // lacing values
+}
+
+// size returns the size of the page payload
+func (p oggPage) size() int64 {
+	var n int64
+	for _, s := range p.segments {
+		n += int64(s)
+	}
+	return n
+}
+
+// readOggPage reads the page header at off
+func readOggPage(r io.ReaderAt, off int64) (oggPage, error) {
+	h, err := readAt(r, off, oggPageHeaderSize)
+	if err != nil {
+		return oggPage{}, err
+	}
+	if string(h[:4]) != "OggS" {
+		return oggPage{}, errNotAudioFormat
+	}
+	p := oggPage{
+		granule: int64(binary.LittleEndian.Uint64(h[6:])),
+		serial:  binary.LittleEndian.Uint32(h[14:]),
+	}
+	p.segments, err = readAt(r, off+oggPageHeaderSize, int(h[26]))
+	return p, err
+}
+
+// oggHeaderPackets returns the first two packets (identification and comment headers) of the first logical stream
+func oggHeaderPackets(r io.ReaderAt) (serial uint32, packets [][]byte, err error) {
+	var (
+		packet []byte
+		total  int
+		off    int64
+	)
+	for first := true; len(packets) < 2; first = false {
+		p, err := readOggPage(r, off)
+		if err != nil {
+			return 0, nil, err
+		}
+		if first {
+			serial = p.serial
+		}
+		payload, err := readAt(r, off+oggPageHeaderSize+int64(len(p.segments)), int(p.size()))
+		if err != nil {
+			return 0, nil, err
+		}
+		off += oggPageHeaderSize + int64(len(p.segments)) + p.size()
+		if p.serial != serial {
+			// multiplexed stream
+			continue
+		}
+		for _, s := range p.segments {
+			packet = append(packet, payload[:s]...)
+			payload = payload[s:]
+			total += int(s)
+			if total > oggMaxHeaderPackets {
+				return 0, nil, io.ErrUnexpectedEOF
+			}
+			if s < 255 {
+				packets = append(packets, packet)
+				packet = nil
+				if len(packets) == 2 {
+					break
+				}
+			}
+		}
+	}
+	return serial, packets, nil
+}
+
+// oggLastGranule returns the granule position of the last page of stream serial, -1 if not found
+func oggLastGranule(r io.ReaderAt, size int64, serial uint32) int64 {
+	start := size - oggTailScan
+	if start < 0 {
+		start = 0
+	}
+	buf, err := readAt(r, start, int(size-start))
+	if err != nil {
+		return -1
+	}
+	for i := bytes.LastIndex(buf, []byte("OggS")); i >= 0; i = bytes.LastIndex(buf[:i], []byte("OggS")) {
+		if i+oggPageHeaderSize > len(buf) {
+			continue
+		}
+		granule := int64(binary.LittleEndian.Uint64(buf[i+6:]))
+		if binary.LittleEndian.Uint32(buf[i+14:]) == serial && granule >= 0 {
+			return granule
+		}
+	}
+	return -1
+}
+
+// probeOgg reads an Ogg Opus or Vorbis file, the duration is the last granule position
+func probeOgg(r io.ReaderAt, size int64) (Info, error) {
+	if magic, err := readAt(r, 0, 4); err != nil || string(magic) != "OggS" {
+		return Info{}, errNotAudioFormat
+	}
+	serial, packets, err := oggHeaderPackets(r)
+	if err != nil {
+		return Info{}, err
+	}
+	id, comment := packets[0], packets[1]
+
+	var (
+		a       Audio
+		tags    Tags
+		vendor  string
+		preSkip int64
+	)
+	switch {
+	case len(id) >= 19 && string(id[:8]) == "OpusHead":
+		a = Audio{
+			Format:       "Opus",
+			Channels:     uint(id[9]),
+			SamplingRate: 48000,
+		}
+		preSkip = int64(binary.LittleEndian.Uint16(id[10:]))
+		if bytes.HasPrefix(comment, []byte("OpusTags")) {
+			vendor, tags = vorbisComments(comment[8:])
+		}
+	case len(id) >= 30 && string(id[:7]) == "\x01vorbis":
+		a = Audio{
+			Format:       "Vorbis",
+			Channels:     uint(id[11]),
+			SamplingRate: uint(binary.LittleEndian.Uint32(id[12:])),
+		}
+		if nominal := int32(binary.LittleEndian.Uint32(id[20:])); nominal > 0 {
+			a.BitRate = float32(nominal)
+		}
+		if bytes.HasPrefix(comment, []byte("\x03vorbis")) {
+			vendor, tags = vorbisComments(comment[7:])
+		}
+	default:
+		return Info{}, errNotAudioFormat
+	}
+	a.CompressionMode = "Lossy"
+
+	if granule := oggLastGranule(r, size, serial); granule > preSkip && a.SamplingRate > 0 {
+		a.SamplingCount = uint(granule - preSkip)
+		a.Duration = float32(float64(a.SamplingCount) / float64(a.SamplingRate))
+		if a.BitRate == 0 {
+			a.BitRate = float32(float64(size) * 8 / float64(a.Duration))
+		}
+	}
+	info := newAudioInfo("Ogg", size, a, tags)
+	info.General.EncodedLibrary = vendor
+	return info, nil
+}
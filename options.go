@@ -0,0 +1,175 @@
+package mediainfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// knownOptions are the libmediainfo options accepted by WithOption and SetGlobalOption (lower case)
+var knownOptions = map[string]bool{
+	"complete":                     true,
+	"cover_data":                   true,
+	"file_testcontinuousfilenames": true,
+	"file_isseekable":              true,
+	"file_forceparser":             true,
+	"info_version":                 true,
+	"inform":                       true,
+	"internet":                     true,
+	"language":                     true,
+	"legacy":                       true,
+	"output":                       true,
+	"parsespeed":                   true,
+	"readbyhuman":                  true,
+	"trace_format":                 true,
+	"trace_level":                  true,
+}
+
+// optionValue is a libmediainfo option and its value
+type optionValue struct {
+	key   string
+	value string
+}
+
+// Options holds the libmediainfo options applied to a single Inform call
+type Options struct {
+	values      []optionValue
+	nativeAudio bool // see WithNativeAudio
+}
+
+// Option configures Options
+type Option func(*Options) error
+
+// newOptions applies opts in order
+func newOptions(opts []Option) (*Options, error) {
+	o := &Options{}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// set replaces the value of key if already set, keeping the order of the first call
+func (o *Options) set(key, value string) {
+	for i := range o.values {
+		if strings.EqualFold(o.values[i].key, key) {
+			o.values[i].value = value
+			return
+		}
+	}
+	o.values = append(o.values, optionValue{key: key, value: value})
+}
+
+func validateOption(key string) error {
+	if !knownOptions[strings.ToLower(key)] {
+		return fmt.Errorf("%w: %s", ErrUnknownOption, key)
+	}
+	return nil
+}
+
+func boolOption(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// WithOption sets any known libmediainfo option (e.g. "ParseSpeed", "0.5")
+func WithOption(key, value string) Option {
+	return func(o *Options) error {
+		if err := validateOption(key); err != nil {
+			return err
+		}
+		o.set(key, value)
+		return nil
+	}
+}
+
+// WithNativeAudio makes Inform analyze the audio-only files supported by InformAudio with its
+// native parsers, faster as only the headers are read. The other files are analyzed by libmediainfo.
+func WithNativeAudio() Option {
+	return func(o *Options) error {
+		o.nativeAudio = true
+		return nil
+	}
+}
+
+// WithParseSpeed sets how much of the file is parsed, from 0 (headers only) to 1 (whole file)
+func WithParseSpeed(speed float32) Option {
+	return func(o *Options) error {
+		if speed < 0 || speed > 1 {
+			return fmt.Errorf("invalid ParseSpeed %v: must be between 0 and 1", speed)
+		}
+		o.set("ParseSpeed", strconv.FormatFloat(float64(speed), 'f', -1, 32))
+		return nil
+	}
+}
+
+// WithComplete requests all the fields libmediainfo knows, not only the most relevant ones
+func WithComplete(complete bool) Option {
+	return func(o *Options) error {
+		o.set("Complete", boolOption(complete))
+		return nil
+	}
+}
+
+// WithLanguage sets the language of the output, "raw" keeps internal names
+func WithLanguage(language string) Option {
+	return func(o *Options) error {
+		o.set("Language", language)
+		return nil
+	}
+}
+
+// WithCoverData sets how cover data is reported (e.g. "base64"), empty disables it
+func WithCoverData(mode string) Option {
+	return func(o *Options) error {
+		o.set("Cover_Data", mode)
+		return nil
+	}
+}
+
+// WithTestContinuousFileNames enables detection of image sequences (file0001.dpx, file0002.dpx...)
+func WithTestContinuousFileNames(enable bool) Option {
+	return func(o *Options) error {
+		o.set("File_TestContinuousFileNames", boolOption(enable))
+		return nil
+	}
+}
+
+// WithReadByHuman enables the human readable variants of the fields
+func WithReadByHuman(enable bool) Option {
+	return func(o *Options) error {
+		o.set("ReadByHuman", boolOption(enable))
+		return nil
+	}
+}
+
+// WithLegacy enables legacy fields
+func WithLegacy(enable bool) Option {
+	return func(o *Options) error {
+		o.set("Legacy", boolOption(enable))
+		return nil
+	}
+}
+
+// WithTraceLevel sets the libmediainfo trace level, 0 disables it
+func WithTraceLevel(level int) Option {
+	return func(o *Options) error {
+		if level < 0 {
+			return fmt.Errorf("invalid Trace_Level %d", level)
+		}
+		o.set("Trace_Level", strconv.Itoa(level))
+		return nil
+	}
+}
+
+// checkOptionResponse converts a libmediainfo option acknowledgement into an error when it reports a failure
+func checkOptionResponse(key, value, response string) error {
+	if strings.HasPrefix(strings.ToLower(response), "option not known") {
+		return &OptionError{Key: key, Value: value, Response: response}
+	}
+	return nil
+}
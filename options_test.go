@@ -0,0 +1,66 @@
+package mediainfo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_newOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    []optionValue
+		wantErr error
+	}{
+		{name: "none"},
+		{
+			name: "typed options",
+			opts: []Option{WithParseSpeed(0.5), WithComplete(true), WithCoverData("base64"), WithTraceLevel(1)},
+			want: []optionValue{{"ParseSpeed", "0.5"}, {"Complete", "1"}, {"Cover_Data", "base64"}, {"Trace_Level", "1"}},
+		},
+		{
+			name: "later option wins",
+			opts: []Option{WithParseSpeed(1), WithLegacy(true), WithOption("parsespeed", "0")},
+			want: []optionValue{{"ParseSpeed", "0"}, {"Legacy", "1"}},
+		},
+		{name: "unknown option", opts: []Option{WithOption("Not_An_Option", "1")}, wantErr: ErrUnknownOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newOptions(tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("newOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.values, tt.want) {
+				t.Errorf("newOptions() = %v, want %v", got.values, tt.want)
+			}
+		})
+	}
+
+	if _, err := newOptions([]Option{WithParseSpeed(2)}); err == nil {
+		t.Errorf("newOptions() expected error for ParseSpeed out of range")
+	}
+}
+
+func TestSetGlobalOption(t *testing.T) {
+	if _, err := SetGlobalOption("Not_An_Option", "1"); !errors.Is(err, ErrUnknownOption) {
+		t.Errorf("SetGlobalOption() error = %v, want %v", err, ErrUnknownOption)
+	}
+	if _, err := SetGlobalOption("ParseSpeed", "1"); !isLoaded() && !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("SetGlobalOption() error = %v, want %v", err, ErrNotLoaded)
+	}
+}
+
+func Test_checkOptionResponse(t *testing.T) {
+	var optErr *OptionError
+	if err := checkOptionResponse("Foo", "1", "Option not known"); !errors.As(err, &optErr) || optErr.Key != "Foo" {
+		t.Errorf("checkOptionResponse() error = %v, want *OptionError", err)
+	}
+	if err := checkOptionResponse("ParseSpeed", "1", ""); err != nil {
+		t.Errorf("checkOptionResponse() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,128 @@
+package mediainfo
+
+import (
+	"io"
+	"io/ioutil"
+)
+
+// bufferChunkSize is the size of the blocks sent to libmediainfo by the buffer API
+const bufferChunkSize = 64 << 10
+
+// InformReader returns the Media details (struct Info) of the data read from r, e.g. an upload,
+// using the libmediainfo buffer API. size is the total size of the data, -1 if unknown
+// (the duration and bit rates of some formats are then not available).
+// r is read once, sequentially: when libmediainfo skips forward the data is discarded, when it
+// asks for data already read (e.g. an index at the end of a MP4 file) the information found so far is returned.
+func InformReader(r io.Reader, size int64, opts ...Option) (Info, error) {
+	return informBuffer(size, opts, func(mi *mediaInfo) error {
+		return feedReader(mi, r, size)
+	})
+}
+
+// InformReaderAt returns the Media details (struct Info) of the size bytes of r using the libmediainfo
+// buffer API. Unlike InformReader, the seek requests of libmediainfo are followed, so only the needed
+// parts are read and the result is the same as Inform on a file with the same content.
+func InformReaderAt(r io.ReaderAt, size int64, opts ...Option) (Info, error) {
+	return informBuffer(size, opts, func(mi *mediaInfo) error {
+		return feedReaderAt(mi, r, size)
+	})
+}
+
+// informBuffer analyzes the size bytes sent by feed with the libmediainfo buffer API
+func informBuffer(size int64, opts []Option, feed func(mi *mediaInfo) error) (Info, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return Info{}, err
+	}
+
+	mi, err := newMediaInfo()
+	if err != nil {
+		return Info{}, err
+	}
+	defer mi.Close()
+	if err := mi.SetOptions(o); err != nil {
+		return Info{}, err
+	}
+
+	mi.OpenBufferInit(size, 0)
+	if err := feed(mi); err != nil {
+		return Info{}, err
+	}
+	mi.OpenBufferFinalize()
+
+	info, err := mi.Inform()
+	if err != nil {
+		return Info{}, err
+	}
+	if !hasFormat(info) {
+		return Info{}, ErrUnsupportedFormat
+	}
+
+	var result Info
+	result.ScanMode = o.scanMode()
+	result.setTracks(info)
+	return result, nil
+}
+
+// feedReaderAt sends the data of r to libmediainfo, from the offsets it asks for, until it is finalized or the data ends
+func feedReaderAt(mi *mediaInfo, r io.ReaderAt, size int64) error {
+	buf := make([]byte, bufferChunkSize)
+	var pos int64
+	for pos < size {
+		n := int64(len(buf))
+		if size-pos < n {
+			n = size - pos
+		}
+		read, err := r.ReadAt(buf[:n], pos)
+		if read == 0 && err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		pos += int64(read)
+		if mi.OpenBufferContinue(buf[:read])&bufferFinalized != 0 {
+			return nil
+		}
+		if to := mi.OpenBufferGoTo(); to != noSeek {
+			pos = to
+			mi.OpenBufferInit(size, to)
+		}
+	}
+	return nil
+}
+
+// feedReader sends the data of r to libmediainfo until it is finalized or r ends
+func feedReader(mi *mediaInfo, r io.Reader, size int64) error {
+	buf := make([]byte, bufferChunkSize)
+	var pos int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			pos += int64(n)
+			if mi.OpenBufferContinue(buf[:n])&bufferFinalized != 0 {
+				return nil
+			}
+			if to := mi.OpenBufferGoTo(); to != noSeek {
+				if to < pos {
+					// r can't go back
+					return nil
+				}
+				if _, err := io.CopyN(ioutil.Discard, r, to-pos); err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+				pos = to
+				mi.OpenBufferInit(size, to)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
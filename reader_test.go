@@ -0,0 +1,45 @@
+package mediainfo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInformReader(t *testing.T) {
+	if _, err := InformReader(strings.NewReader("data"), 4); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("InformReader() error = %v, want %v", err, ErrNotLoaded)
+	}
+
+	if !Load() {
+		t.Fatalf("Failed to load dll/shared object")
+	}
+	defer Unload()
+
+	path := filepath.Join("testdata", "1_video_1_audio_1_menu.mkv")
+	want, err := Inform(path)
+	if err != nil {
+		t.Fatalf("Inform() error = %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fi, _ := f.Stat()
+
+	got, err := InformReader(f, fi.Size())
+	if err != nil {
+		t.Fatalf("InformReader() error = %v", err)
+	}
+	if got.General.Format != want.General.Format || got.General.Duration != want.General.Duration ||
+		len(got.VideoTracks) != len(want.VideoTracks) || len(got.AudioTracks) != len(want.AudioTracks) {
+		t.Errorf("InformReader()\nGot \n%+v\nWant\n%+v", got, want)
+	}
+
+	if _, err := InformReader(strings.NewReader(strings.Repeat("not media ", 100)), 1000); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("InformReader() error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
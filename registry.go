@@ -0,0 +1,170 @@
+package mediainfo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Prober analyzes the files of a format, e.g. an in-house format unknown to libmediainfo.
+// It returns an error wrapping ErrUnsupportedFormat if r is not of its format, the next Prober
+// or libmediainfo is then used.
+type Prober interface {
+	Probe(r io.ReaderAt, size int64) (Info, error)
+}
+
+// ProberFunc is a function used as a Prober
+type ProberFunc func(r io.ReaderAt, size int64) (Info, error)
+
+// Probe implements Prober
+func (f ProberFunc) Probe(r io.ReaderAt, size int64) (Info, error) {
+	return f(r, size)
+}
+
+// PostProcessor enriches the Info returned by Inform, e.g. with fields derived from the tracks
+// or from a sidecar file of path. An error is returned by Inform with the Info processed so far.
+type PostProcessor interface {
+	PostProcess(path string, info *Info) error
+}
+
+// PostProcessorFunc is a function used as a PostProcessor
+type PostProcessorFunc func(path string, info *Info) error
+
+// PostProcess implements PostProcessor
+func (f PostProcessorFunc) PostProcess(path string, info *Info) error {
+	return f(path, info)
+}
+
+// ProbeMatch selects the files analyzed by a Prober: a file matches if its extension is one of
+// Extensions or if it has the bytes Magic at Offset
+type ProbeMatch struct {
+	Extensions []string // without dot, e.g. "xmp", case insensitive
+	Magic      []byte
+	Offset     int64
+}
+
+// matches reports whether the file of extension ext and first bytes header matches m
+func (m ProbeMatch) matches(ext string, header []byte) bool {
+	for _, e := range m.Extensions {
+		if strings.EqualFold(strings.TrimPrefix(e, "."), ext) {
+			return true
+		}
+	}
+	end := m.Offset + int64(len(m.Magic))
+	return len(m.Magic) > 0 && end <= int64(len(header)) && bytes.Equal(header[m.Offset:end], m.Magic)
+}
+
+type registeredProber struct {
+	match  ProbeMatch
+	prober Prober
+}
+
+var (
+	registryMu     sync.RWMutex
+	probers        []registeredProber
+	postProcessors []PostProcessor
+)
+
+// RegisterProber registers p for the files matching m. Inform uses the registered Probers,
+// in registration order, before libmediainfo. It panics if m.Offset is negative or p is nil.
+func RegisterProber(m ProbeMatch, p Prober) {
+	if m.Offset < 0 {
+		panic("mediainfo: RegisterProber with a negative offset")
+	}
+	if p == nil {
+		panic("mediainfo: RegisterProber with a nil Prober")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	probers = append(probers, registeredProber{match: m, prober: p})
+}
+
+// RegisterPostProcessor registers p, run by Inform in registration order once the tracks are set
+func RegisterPostProcessor(p PostProcessor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	postProcessors = append(postProcessors, p)
+}
+
+// probe analyzes the file at path with the first registered Prober matching it.
+// ok is false if no Prober matches or they all return ErrUnsupportedFormat.
+func probe(path string) (info Info, ok bool, err error) {
+	registryMu.RLock()
+	n := len(probers)
+	registryMu.RUnlock()
+	if n == 0 {
+		return Info{}, false, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		// reported by libmediainfo
+		return Info{}, false, nil
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return Info{}, false, nil
+	}
+	return probeReaderAt(f, fi.Size(), path)
+}
+
+// probeReaderAt analyzes r, the content of the file path of size bytes, as probe
+func probeReaderAt(r io.ReaderAt, size int64, path string) (info Info, ok bool, err error) {
+	registryMu.RLock()
+	candidates := probers
+	registryMu.RUnlock()
+	if len(candidates) == 0 {
+		return Info{}, false, nil
+	}
+
+	var headerSize int64
+	for _, c := range candidates {
+		if end := c.match.Offset + int64(len(c.match.Magic)); end > headerSize {
+			headerSize = end
+		}
+	}
+	header := make([]byte, headerSize)
+	n, _ := r.ReadAt(header, 0)
+	header = header[:n]
+
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	for _, c := range candidates {
+		if !c.match.matches(ext, header) {
+			continue
+		}
+		info, err := c.prober.Probe(r, size)
+		if errors.Is(err, ErrUnsupportedFormat) {
+			continue
+		}
+		if err != nil {
+			return Info{}, false, &OpenError{Path: path, Err: err}
+		}
+		info.General.CompleteName = path
+		if info.General.FileExtension == "" {
+			info.General.FileExtension = strings.ToLower(ext)
+		}
+		if info.General.FileSize == 0 {
+			info.General.FileSize = uint(size)
+		}
+		return info, true, nil
+	}
+	return Info{}, false, nil
+}
+
+// postProcess runs the registered PostProcessors on info
+func postProcess(path string, info *Info) error {
+	registryMu.RLock()
+	pp := postProcessors
+	registryMu.RUnlock()
+	for _, p := range pp {
+		if err := p.PostProcess(path, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
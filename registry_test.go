@@ -0,0 +1,132 @@
+package mediainfo
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// withRegistry runs f with empty registered Probers and PostProcessors
+func withRegistry(t *testing.T, f func()) {
+	registryMu.Lock()
+	savedProbers, savedPostProcessors := probers, postProcessors
+	probers, postProcessors = nil, nil
+	registryMu.Unlock()
+	defer func() {
+		registryMu.Lock()
+		probers, postProcessors = savedProbers, savedPostProcessors
+		registryMu.Unlock()
+	}()
+	f()
+}
+
+func TestProbeMatch_matches(t *testing.T) {
+	tests := []struct {
+		name   string
+		match  ProbeMatch
+		ext    string
+		header []byte
+		want   bool
+	}{
+		{"extension", ProbeMatch{Extensions: []string{"xmp"}}, "XMP", nil, true},
+		{"extension with dot", ProbeMatch{Extensions: []string{".sub"}}, "sub", nil, true},
+		{"other extension", ProbeMatch{Extensions: []string{"xmp"}}, "mkv", []byte("XMP"), false},
+		{"magic", ProbeMatch{Magic: []byte("RAWS")}, "bin", []byte("RAWS0001"), true},
+		{"magic at offset", ProbeMatch{Magic: []byte("RAWS"), Offset: 4}, "bin", []byte("0001RAWS"), true},
+		{"short header", ProbeMatch{Magic: []byte("RAWS"), Offset: 4}, "bin", []byte("0001RAW"), false},
+		{"no magic", ProbeMatch{}, "bin", []byte("RAWS"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match.matches(tt.ext, tt.header); got != tt.want {
+				t.Errorf("ProbeMatch.matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterProber(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	raw := filepath.Join(dir, "clip.raws")
+	other := filepath.Join(dir, "clip.bin")
+	if err := ioutil.WriteFile(raw, []byte("RAWS0001 sidecar"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(other, []byte("OTHER"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	withRegistry(t, func() {
+		var calls []string
+		RegisterProber(ProbeMatch{Extensions: []string{"bin"}}, ProberFunc(func(r io.ReaderAt, size int64) (Info, error) {
+			calls = append(calls, "bin")
+			return Info{}, ErrUnsupportedFormat
+		}))
+		RegisterProber(ProbeMatch{Extensions: []string{"bin"}}, ProberFunc(func(r io.ReaderAt, size int64) (Info, error) {
+			calls = append(calls, "wrapped")
+			return Info{}, &OpenError{Err: ErrUnsupportedFormat}
+		}))
+		RegisterProber(ProbeMatch{Magic: []byte("RAWS")}, ProberFunc(func(r io.ReaderAt, size int64) (Info, error) {
+			calls = append(calls, "raws")
+			b, err := readAt(r, 4, 4)
+			if err != nil {
+				return Info{}, err
+			}
+			return Info{General: General{Format: "RAWS", FormatVersion: string(b)}}, nil
+		}))
+		RegisterPostProcessor(PostProcessorFunc(func(path string, info *Info) error {
+			info.General.Title = "processed " + filepath.Base(path)
+			return nil
+		}))
+
+		got, err := Inform(raw)
+		if err != nil {
+			t.Fatalf("Inform() error = %v", err)
+		}
+		want := General{CompleteName: raw, Format: "RAWS", FormatVersion: "0001", FileExtension: "raws", FileSize: 16, Title: "processed clip.raws"}
+		if !reflect.DeepEqual(got.General, want) {
+			t.Errorf("Inform()\nGot \n%+v\nWant\n%+v", got.General, want)
+		}
+		if want := []string{"raws"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Probers called = %v, want %v", calls, want)
+		}
+
+		// not recognized by the bin Prober, analyzed by libmediainfo
+		calls = nil
+		if _, err := Inform(other); !errors.Is(err, ErrNotLoaded) {
+			t.Errorf("Inform() error = %v, want %v", err, ErrNotLoaded)
+		}
+		if want := []string{"bin", "wrapped"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Probers called = %v, want %v", calls, want)
+		}
+
+		errFailed := errors.New("failed")
+		RegisterPostProcessor(PostProcessorFunc(func(path string, info *Info) error {
+			return errFailed
+		}))
+		if got, err := Inform(raw); err != errFailed || got.General.Title != "processed clip.raws" {
+			t.Errorf("Inform() = %+v, %v, want %v", got.General, err, errFailed)
+		}
+	})
+}
+
+func TestRegisterProber_negativeOffset(t *testing.T) {
+	withRegistry(t, func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("RegisterProber() with a negative offset, want panic")
+			}
+		}()
+		RegisterProber(ProbeMatch{Magic: []byte("RAWS"), Offset: -1}, ProberFunc(func(r io.ReaderAt, size int64) (Info, error) {
+			return Info{}, nil
+		}))
+	})
+}
@@ -0,0 +1,121 @@
+package mediainfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScanMode represents how deep libmediainfo parses a file
+type ScanMode int
+
+const (
+	// ScanNative is the ScanMode of the Info not produced by libmediainfo
+	// (native parsers, Probers): its values are read from the headers, not estimated
+	ScanNative ScanMode = iota
+	// ScanDefault uses the libmediainfo default depth (ParseSpeed 0.5)
+	ScanDefault ScanMode = iota
+	// ScanHeader only reads the headers, enough to know the container and the tracks
+	ScanHeader
+	// ScanQuick parses a small part of the file, good for triage
+	ScanQuick
+	// ScanFull parses the whole file, counts and sizes are exact
+	ScanFull
+)
+
+// parseSpeeds maps each ScanMode to the libmediainfo ParseSpeed option
+var parseSpeeds = map[ScanMode]float32{
+	ScanHeader:  0,
+	ScanQuick:   0.25,
+	ScanDefault: 0.5,
+	ScanFull:    1,
+}
+
+func (m ScanMode) String() string {
+	switch m {
+	case ScanNative:
+		return "Native"
+	case ScanDefault:
+		return "Default"
+	case ScanHeader:
+		return "Header"
+	case ScanQuick:
+		return "Quick"
+	case ScanFull:
+		return "Full"
+	}
+	return "ScanMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// WithScanMode sets the ParseSpeed option matching m
+func WithScanMode(m ScanMode) Option {
+	return func(o *Options) error {
+		speed, ok := parseSpeeds[m]
+		if !ok {
+			return fmt.Errorf("invalid scan mode: %s", m)
+		}
+		return WithParseSpeed(speed)(o)
+	}
+}
+
+// scanMode returns the ScanMode matching the ParseSpeed option, ScanDefault if not set
+func (o *Options) scanMode() ScanMode {
+	for _, kv := range o.values {
+		if !strings.EqualFold(kv.key, "ParseSpeed") {
+			continue
+		}
+		speed, err := strconv.ParseFloat(kv.value, 32)
+		switch {
+		case err != nil:
+		case speed >= 1:
+			return ScanFull
+		case speed == 0:
+			return ScanHeader
+		case speed < float64(parseSpeeds[ScanDefault]):
+			return ScanQuick
+		}
+	}
+	return ScanDefault
+}
+
+// Estimated returns the fields of i which values are estimates since libmediainfo did not fully parse
+// the file (see ScanMode): the counts, sizes and bit rates it derives from the parsed part of the streams.
+// Fields are named as in Go, e.g. "VideoTracks[0].FrameCount". Zero values are not reported.
+func (i Info) Estimated() []string {
+	if i.ScanMode == ScanNative || i.ScanMode == ScanFull {
+		return nil
+	}
+
+	var fields []string
+	add := func(name string, value float64) {
+		if value != 0 {
+			fields = append(fields, name)
+		}
+	}
+
+	add("General.FrameCount", float64(i.General.FrameCount))
+	add("General.OverallBitRate", float64(i.General.OverallBitRate))
+	for n, v := range i.VideoTracks {
+		prefix := fmt.Sprintf("VideoTracks[%d].", n)
+		add(prefix+"FrameCount", float64(v.FrameCount))
+		add(prefix+"BitRate", float64(v.BitRate))
+		add(prefix+"StreamSize", float64(v.StreamSize))
+		add(prefix+"StreamSizeProportion", float64(v.StreamSizeProportion))
+	}
+	for n, a := range i.AudioTracks {
+		prefix := fmt.Sprintf("AudioTracks[%d].", n)
+		add(prefix+"FrameCount", float64(a.FrameCount))
+		add(prefix+"SamplingCount", float64(a.SamplingCount))
+		add(prefix+"BitRate", float64(a.BitRate))
+		add(prefix+"StreamSize", float64(a.StreamSize))
+		add(prefix+"StreamSizeProportion", float64(a.StreamSizeProportion))
+	}
+	for n, t := range i.TextTracks {
+		prefix := fmt.Sprintf("TextTracks[%d].", n)
+		add(prefix+"FrameCount", float64(t.FrameCount))
+		add(prefix+"ElementCount", float64(t.ElementCount))
+		add(prefix+"BitRate", float64(t.BitRate))
+		add(prefix+"StreamSize", float64(t.StreamSize))
+	}
+	return fields
+}
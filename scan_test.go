@@ -0,0 +1,69 @@
+package mediainfo
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWithScanMode(t *testing.T) {
+	tests := []struct {
+		mode ScanMode
+		want string
+	}{
+		{mode: ScanHeader, want: "0"},
+		{mode: ScanQuick, want: "0.25"},
+		{mode: ScanDefault, want: "0.5"},
+		{mode: ScanFull, want: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			o, err := newOptions([]Option{WithScanMode(tt.mode)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []optionValue{{"ParseSpeed", tt.want}}; !reflect.DeepEqual(o.values, want) {
+				t.Errorf("WithScanMode() = %v, want %v", o.values, want)
+			}
+			if got := o.scanMode(); got != tt.mode {
+				t.Errorf("scanMode() = %v, want %v", got, tt.mode)
+			}
+		})
+	}
+
+	for _, m := range []ScanMode{ScanNative, ScanMode(42)} {
+		if _, err := newOptions([]Option{WithScanMode(m)}); err == nil {
+			t.Errorf("WithScanMode(%v) expected error", m)
+		}
+	}
+}
+
+func TestInfo_Estimated(t *testing.T) {
+	info := Info{
+		General:     General{OverallBitRate: 18},
+		VideoTracks: []Video{{FrameCount: 97974, StreamSize: 1024}},
+		AudioTracks: []Audio{{SamplingCount: 196145040}},
+		ScanMode:    ScanDefault,
+	}
+	want := []string{"General.OverallBitRate", "VideoTracks[0].FrameCount", "VideoTracks[0].StreamSize", "AudioTracks[0].SamplingCount"}
+	if got := info.Estimated(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Info.Estimated() = %v, want %v", got, want)
+	}
+
+	info.ScanMode = ScanFull
+	if got := info.Estimated(); got != nil {
+		t.Errorf("Info.Estimated() = %v, want nil", got)
+	}
+
+	// values read from the headers by the native parsers
+	native, err := readAVI(bytes.NewReader(testAVI(true)), int64(len(testAVI(true))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if native.ScanMode != ScanNative || len(native.VideoTracks) == 0 || native.VideoTracks[0].FrameCount == 0 {
+		t.Fatalf("readAVI() = %+v, want a video track with FrameCount", native)
+	}
+	if got := native.Estimated(); got != nil {
+		t.Errorf("Info.Estimated() of a native parser = %v, want nil", got)
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// durationBuckets are the upper bounds in seconds of the analysis duration histogram
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// histogram is a Prometheus histogram without labels
+type histogram struct {
+	counts []uint64 // by bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// metrics are the server metrics, written in the Prometheus text format
+type metrics struct {
+	mu          sync.Mutex
+	requests    map[[2]string]uint64 // by handler and status code
+	durations   map[string]*histogram
+	inFlight    int
+	rejected    uint64
+	uploadBytes uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:  make(map[[2]string]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// request counts a request of handler answered with code
+func (m *metrics) request(handler string, code int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[[2]string{handler, fmt.Sprint(code)}]++
+}
+
+// observe records the duration of an analysis of source (upload or path)
+func (m *metrics) observe(source string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.durations[source]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(durationBuckets))}
+		m.durations[source] = h
+	}
+	s := d.Seconds()
+	for i, le := range durationBuckets {
+		if s <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += s
+	h.count++
+}
+
+// addInFlight adds n to the number of running analyses
+func (m *metrics) addInFlight(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight += n
+}
+
+// reject counts a request rejected by the concurrency limit
+func (m *metrics) reject() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rejected++
+}
+
+// upload counts n uploaded bytes
+func (m *metrics) upload(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploadBytes += uint64(n)
+}
+
+// writeTo writes the metrics in the Prometheus text exposition format
+func (m *metrics) writeTo(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP mediainfo_http_requests_total HTTP requests by handler and status code.\n")
+	b.WriteString("# TYPE mediainfo_http_requests_total counter\n")
+	keys := make([][2]string, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || (keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1])
+	})
+	for _, k := range keys {
+		fmt.Fprintf(&b, "mediainfo_http_requests_total{handler=%q,code=%q} %d\n", k[0], k[1], m.requests[k])
+	}
+
+	b.WriteString("# HELP mediainfo_inform_duration_seconds Duration of the analyses by source.\n")
+	b.WriteString("# TYPE mediainfo_inform_duration_seconds histogram\n")
+	sources := make([]string, 0, len(m.durations))
+	for s := range m.durations {
+		sources = append(sources, s)
+	}
+	sort.Strings(sources)
+	for _, s := range sources {
+		h := m.durations[s]
+		var cumulative uint64
+		for i, le := range durationBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "mediainfo_inform_duration_seconds_bucket{source=%q,le=\"%g\"} %d\n", s, le, cumulative)
+		}
+		fmt.Fprintf(&b, "mediainfo_inform_duration_seconds_bucket{source=%q,le=\"+Inf\"} %d\n", s, h.count)
+		fmt.Fprintf(&b, "mediainfo_inform_duration_seconds_sum{source=%q} %g\n", s, h.sum)
+		fmt.Fprintf(&b, "mediainfo_inform_duration_seconds_count{source=%q} %d\n", s, h.count)
+	}
+
+	b.WriteString("# HELP mediainfo_inform_in_flight Analyses running.\n")
+	b.WriteString("# TYPE mediainfo_inform_in_flight gauge\n")
+	fmt.Fprintf(&b, "mediainfo_inform_in_flight %d\n", m.inFlight)
+	b.WriteString("# HELP mediainfo_inform_rejected_total Requests rejected by the concurrency limit.\n")
+	b.WriteString("# TYPE mediainfo_inform_rejected_total counter\n")
+	fmt.Fprintf(&b, "mediainfo_inform_rejected_total %d\n", m.rejected)
+	b.WriteString("# HELP mediainfo_upload_bytes_total Bytes read from uploads.\n")
+	b.WriteString("# TYPE mediainfo_upload_bytes_total counter\n")
+	fmt.Fprintf(&b, "mediainfo_upload_bytes_total %d\n", m.uploadBytes)
+
+	io.WriteString(w, b.String())
+}
@@ -0,0 +1,361 @@
+// Package server serves mediainfo.Inform as a REST API, for services that can't link libmediainfo.
+//
+// Endpoints:
+//
+//	POST /inform          analyzes the request body (the uploaded file)
+//	GET  /inform?path=    analyzes a file under one of Config.Roots
+//	GET  /healthz         liveness, always 200
+//	GET  /readyz          readiness, 200 once libmediainfo is loaded
+//	GET  /metrics         Prometheus metrics
+//
+// The analyses return the JSON form of mediainfo.Info; errors return {"error": "..."} with
+// 400, 403, 404, 413, 415, 503 or 504 status codes.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/prcoito/mediainfo"
+)
+
+// Defaults of Config
+const (
+	DefaultMaxUploadSize = 1 << 30
+	DefaultTimeout       = time.Minute
+)
+
+// statusClientClosedRequest is the status code of the requests canceled by the client (nginx 499),
+// only seen in the logs and metrics
+const statusClientClosedRequest = 499
+
+var (
+	errTooLarge    = errors.New("request body too large")
+	errForbidden   = errors.New("path outside of the allowed roots")
+	errMissingPath = errors.New("missing path parameter")
+	errBusy        = errors.New("too many concurrent analyses")
+)
+
+// Config configures a Server
+type Config struct {
+	Roots         []string           // directories GET /inform?path= may read, GET is forbidden if empty
+	MaxUploadSize int64              // maximum POST body size in bytes, DefaultMaxUploadSize if 0
+	MaxConcurrent int                // maximum number of concurrent analyses, runtime.NumCPU() if 0
+	Timeout       time.Duration      // maximum duration of a request, waiting included, DefaultTimeout if 0
+	Options       []mediainfo.Option // libmediainfo options of every analysis
+}
+
+// Server is the http.Handler of the REST API
+type Server struct {
+	cfg     Config
+	roots   []string // absolute, as configured and with symbolic links resolved
+	sem     chan struct{}
+	metrics *metrics
+	mux     *http.ServeMux
+
+	// replaced in tests
+	inform       func(path string, opts ...mediainfo.Option) (mediainfo.Info, error)
+	informReader func(r io.Reader, size int64, opts ...mediainfo.Option) (mediainfo.Info, error)
+	ready        func() bool
+}
+
+// New returns a Server, an error if a root does not exist.
+// libmediainfo must be loaded by the caller (see mediainfo.Load) for the server to be ready.
+func New(cfg Config) (*Server, error) {
+	if cfg.MaxUploadSize == 0 {
+		cfg.MaxUploadSize = DefaultMaxUploadSize
+	}
+	if cfg.MaxConcurrent == 0 {
+		cfg.MaxConcurrent = runtime.NumCPU()
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	s := &Server{
+		cfg:          cfg,
+		sem:          make(chan struct{}, cfg.MaxConcurrent),
+		metrics:      newMetrics(),
+		mux:          http.NewServeMux(),
+		inform:       mediainfo.Inform,
+		informReader: mediainfo.InformReader,
+		ready: func() bool {
+			_, err := mediainfo.LibraryVersion()
+			return err == nil
+		},
+	}
+	for _, root := range cfg.Roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("root %s: %w", root, err)
+		}
+		real, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, fmt.Errorf("root %s: %w", root, err)
+		}
+		s.roots = append(s.roots, abs)
+		if real != abs {
+			s.roots = append(s.roots, real)
+		}
+	}
+
+	s.mux.HandleFunc("/inform", s.handleInform)
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	s.mux.HandleFunc("/readyz", s.handleReady)
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// statusRecorder records the status code of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (s *Server) handleInform(w http.ResponseWriter, r *http.Request) {
+	rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+	handler := "inform_path"
+	switch r.Method {
+	case http.MethodPost:
+		handler = "inform_upload"
+		s.handleUpload(rec, r)
+	case http.MethodGet, http.MethodHead:
+		s.handlePath(rec, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(rec, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+	s.metrics.request(handler, rec.code)
+}
+
+// handleUpload analyzes the request body with the libmediainfo buffer API.
+// The analysis runs in the handler so that the body is never read after it returns.
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.ContentLength > s.cfg.MaxUploadSize {
+		writeError(w, http.StatusRequestEntityTooLarge, errTooLarge)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.Timeout)
+	defer cancel()
+	if err := s.acquire(ctx); err != nil {
+		s.writeResult(w, mediainfo.Info{}, err)
+		return
+	}
+	defer s.release()
+
+	body := &limitedReader{r: r.Body, ctx: ctx, remaining: s.cfg.MaxUploadSize}
+	start := time.Now()
+	info, err := s.informReader(body, r.ContentLength, s.cfg.Options...)
+	s.metrics.observe("upload", time.Since(start))
+	s.metrics.upload(s.cfg.MaxUploadSize - body.remaining)
+	if body.err != nil {
+		// the reader error is the cause, libmediainfo only saw a short file
+		err = body.err
+	}
+	s.writeResult(w, info, err)
+}
+
+// handlePath analyzes the file of the path parameter
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request) {
+	path, err := s.resolve(r.URL.Query().Get("path"))
+	if err != nil {
+		s.writeResult(w, mediainfo.Info{}, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.Timeout)
+	defer cancel()
+	if err := s.acquire(ctx); err != nil {
+		s.writeResult(w, mediainfo.Info{}, err)
+		return
+	}
+
+	type result struct {
+		info mediainfo.Info
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		// libmediainfo can't be interrupted, the slot is released when it returns
+		defer s.release()
+		start := time.Now()
+		info, err := s.inform(path, s.cfg.Options...)
+		s.metrics.observe("path", time.Since(start))
+		done <- result{info, err}
+	}()
+	select {
+	case res := <-done:
+		s.writeResult(w, res.info, res.err)
+	case <-ctx.Done():
+		s.writeResult(w, mediainfo.Info{}, ctx.Err())
+	}
+}
+
+// resolve returns the absolute path of p with symbolic links resolved, if under one of the roots
+func (s *Server) resolve(p string) (string, error) {
+	if p == "" {
+		return "", errMissingPath
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	// checked before and after resolving the links: the existence of files outside the roots is not disclosed
+	if !s.underRoot(abs) {
+		return "", errForbidden
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	if !s.underRoot(real) {
+		return "", errForbidden
+	}
+	return real, nil
+}
+
+// underRoot reports whether the absolute path p is one of the roots or in one of them
+func (s *Server) underRoot(p string) bool {
+	for _, root := range s.roots {
+		rel, err := filepath.Rel(root, p)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// acquire takes an analysis slot: errBusy if none is free before ctx ends,
+// the error of ctx if the client is gone
+func (s *Server) acquire(ctx context.Context) error {
+	if err := ctx.Err(); errors.Is(err, context.Canceled) {
+		return err
+	}
+	select {
+	case s.sem <- struct{}{}:
+		s.metrics.addInFlight(1)
+		return nil
+	case <-ctx.Done():
+		if err := ctx.Err(); errors.Is(err, context.Canceled) {
+			return err
+		}
+		s.metrics.reject()
+		return errBusy
+	}
+}
+
+// release frees an analysis slot
+func (s *Server) release() {
+	s.metrics.addInFlight(-1)
+	<-s.sem
+}
+
+// writeResult writes info as JSON, or err with its status code
+func (s *Server) writeResult(w http.ResponseWriter, info mediainfo.Info, err error) {
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// statusCode returns the HTTP status code of an analysis error
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, errMissingPath):
+		return http.StatusBadRequest
+	case errors.Is(err, errForbidden), errors.Is(err, os.ErrPermission):
+		return http.StatusForbidden
+	case errors.Is(err, os.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, errTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, mediainfo.ErrUnsupportedFormat):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, mediainfo.ErrNotLoaded), errors.Is(err, errBusy):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// writeError writes err as {"error": "..."}
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if code == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	io.WriteString(w, "ok\n")
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if !s.ready() {
+		http.Error(w, "libmediainfo not loaded", http.StatusServiceUnavailable)
+		return
+	}
+	io.WriteString(w, "ok\n")
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	s.metrics.writeTo(w)
+}
+
+// limitedReader reads at most remaining bytes from r while ctx is not done
+type limitedReader struct {
+	r         io.Reader
+	ctx       context.Context
+	remaining int64
+	err       error // errTooLarge, the ctx error or the read error which stopped the reads
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if err := l.ctx.Err(); err != nil {
+		l.err = err
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		n, err = int(l.remaining), errTooLarge
+	}
+	l.remaining -= int64(n)
+	if err != nil && err != io.EOF {
+		l.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prcoito/mediainfo"
+)
+
+// newTestServer returns a server with fake analyses: the format is the content of the file or upload
+func newTestServer(t *testing.T, cfg Config) *Server {
+	t.Helper()
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	s.inform = func(path string, opts ...mediainfo.Option) (mediainfo.Info, error) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return mediainfo.Info{}, &mediainfo.OpenError{Path: path, Err: err}
+		}
+		return fakeInfo(b)
+	}
+	s.informReader = func(r io.Reader, size int64, opts ...mediainfo.Option) (mediainfo.Info, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return mediainfo.Info{}, err
+		}
+		return fakeInfo(b)
+	}
+	s.ready = func() bool { return true }
+	return s
+}
+
+func fakeInfo(b []byte) (mediainfo.Info, error) {
+	if len(b) == 0 {
+		return mediainfo.Info{}, mediainfo.ErrUnsupportedFormat
+	}
+	return mediainfo.Info{General: mediainfo.General{Format: string(b), FileSize: uint(len(b))}}, nil
+}
+
+// do serves a request and returns the status code and body
+func do(s *Server, method, target string, body io.Reader) (int, string) {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, target, body))
+	return w.Code, w.Body.String()
+}
+
+func TestServer_Upload(t *testing.T) {
+	s := newTestServer(t, Config{MaxUploadSize: 10})
+
+	code, body := do(s, http.MethodPost, "/inform", strings.NewReader("Matroska"))
+	var info mediainfo.Info
+	if err := json.Unmarshal([]byte(body), &info); err != nil || code != http.StatusOK || info.General.Format != "Matroska" {
+		t.Errorf("POST /inform = %d %s", code, body)
+	}
+
+	tests := []struct {
+		name string
+		body io.Reader
+		want int
+	}{
+		{"empty", strings.NewReader(""), http.StatusUnsupportedMediaType},
+		{"content length too large", strings.NewReader("a large upload"), http.StatusRequestEntityTooLarge},
+		// no content length
+		{"chunked too large", io.MultiReader(strings.NewReader("a large "), strings.NewReader("upload")), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		if code, body := do(s, http.MethodPost, "/inform", tt.body); code != tt.want || !strings.Contains(body, `"error"`) {
+			t.Errorf("%s: POST /inform = %d %s, want %d", tt.name, code, body, tt.want)
+		}
+	}
+}
+
+func TestServer_Path(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "media")
+	os.Mkdir(root, 0755)
+	ioutil.WriteFile(filepath.Join(root, "movie.mkv"), []byte("Matroska"), 0644)
+	secret := filepath.Join(dir, "secret.mkv")
+	ioutil.WriteFile(secret, []byte("secret"), 0644)
+	os.Symlink(secret, filepath.Join(root, "link.mkv"))
+
+	s := newTestServer(t, Config{Roots: []string{root}})
+	tests := []struct {
+		path string
+		want int
+	}{
+		{filepath.Join(root, "movie.mkv"), http.StatusOK},
+		{filepath.Join(root, "missing.mkv"), http.StatusNotFound},
+		{secret, http.StatusForbidden},
+		{filepath.Join(root, "..", "secret.mkv"), http.StatusForbidden},
+		{filepath.Join(root, "link.mkv"), http.StatusForbidden},
+		{"", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		code, body := do(s, http.MethodGet, "/inform?path="+url.QueryEscape(tt.path), nil)
+		if code != tt.want {
+			t.Errorf("GET /inform?path=%s = %d %s, want %d", tt.path, code, body, tt.want)
+		}
+	}
+
+	if code, _ := do(s, http.MethodDelete, "/inform", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE /inform = %d, want 405", code)
+	}
+
+	noRoots := newTestServer(t, Config{})
+	if code, _ := do(noRoots, http.MethodGet, "/inform?path="+url.QueryEscape(filepath.Join(root, "movie.mkv")), nil); code != http.StatusForbidden {
+		t.Errorf("GET without roots = %d, want 403", code)
+	}
+	if _, err := New(Config{Roots: []string{filepath.Join(dir, "missing")}}); err == nil {
+		t.Errorf("New() with a missing root, want error")
+	}
+}
+
+func TestServer_Limits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "movie.mkv"), []byte("Matroska"), 0644)
+	target := "/inform?path=" + url.QueryEscape(filepath.Join(dir, "movie.mkv"))
+
+	s := newTestServer(t, Config{Roots: []string{dir}, MaxConcurrent: 1, Timeout: 50 * time.Millisecond})
+	unblock := make(chan struct{})
+	s.inform = func(path string, opts ...mediainfo.Option) (mediainfo.Info, error) {
+		<-unblock
+		return mediainfo.Info{}, nil
+	}
+
+	// the first analysis times out and keeps its slot until it returns
+	if code, _ := do(s, http.MethodGet, target, nil); code != http.StatusGatewayTimeout {
+		t.Errorf("GET = %d, want 504", code)
+	}
+	if code, _ := do(s, http.MethodPost, "/inform", strings.NewReader("Matroska")); code != http.StatusServiceUnavailable {
+		t.Errorf("POST while busy = %d, want 503", code)
+	}
+	close(unblock)
+	// the slot is released once the analysis returns
+	for deadline := time.Now().Add(5 * time.Second); len(s.sem) > 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("analysis slot not released")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if code, _ := do(s, http.MethodPost, "/inform", strings.NewReader("Matroska")); code != http.StatusOK {
+		t.Errorf("POST = %d, want 200", code)
+	}
+
+	_, metrics := do(s, http.MethodGet, "/metrics", nil)
+	for _, want := range []string{
+		`mediainfo_http_requests_total{handler="inform_path",code="504"} 1`,
+		`mediainfo_http_requests_total{handler="inform_upload",code="503"} 1`,
+		`mediainfo_inform_duration_seconds_count{source="upload"} 1`,
+		`mediainfo_inform_in_flight 0`,
+		`mediainfo_inform_rejected_total 1`,
+		`mediainfo_upload_bytes_total 8`,
+	} {
+		if !strings.Contains(metrics, want+"\n") {
+			t.Errorf("metrics missing %s in\n%s", want, metrics)
+		}
+	}
+}
+
+func TestServer_ClientCanceled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "movie.mkv"), []byte("Matroska"), 0644)
+
+	s := newTestServer(t, Config{Roots: []string{dir}})
+	unblock := make(chan struct{})
+	defer close(unblock)
+	s.inform = func(path string, opts ...mediainfo.Option) (mediainfo.Info, error) {
+		<-unblock
+		return mediainfo.Info{}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/inform?path="+url.QueryEscape(filepath.Join(dir, "movie.mkv")), nil)
+	s.ServeHTTP(w, r.WithContext(ctx))
+	if w.Code != statusClientClosedRequest {
+		t.Errorf("GET canceled = %d, want %d", w.Code, statusClientClosedRequest)
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	s := newTestServer(t, Config{})
+	if code, _ := do(s, http.MethodGet, "/healthz", nil); code != http.StatusOK {
+		t.Errorf("GET /healthz = %d", code)
+	}
+	if code, _ := do(s, http.MethodGet, "/readyz", nil); code != http.StatusOK {
+		t.Errorf("GET /readyz = %d", code)
+	}
+	s.ready = func() bool { return false }
+	if code, _ := do(s, http.MethodGet, "/readyz", nil); code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz not loaded = %d, want 503", code)
+	}
+}
+
+func Test_limitedReader(t *testing.T) {
+	l := &limitedReader{r: bytes.NewReader(make([]byte, 10)), ctx: httptest.NewRequest("GET", "/", nil).Context(), remaining: 10}
+	if b, err := ioutil.ReadAll(l); len(b) != 10 || err != nil {
+		t.Errorf("ReadAll() = %d, %v", len(b), err)
+	}
+	l = &limitedReader{r: bytes.NewReader(make([]byte, 11)), ctx: l.ctx, remaining: 10}
+	if _, err := ioutil.ReadAll(l); err != errTooLarge {
+		t.Errorf("ReadAll() error = %v, want errTooLarge", err)
+	}
+}
@@ -0,0 +1,357 @@
+package spec
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prcoito/mediainfo"
+)
+
+// segment represents a part of a rule path: Field, Field[selector] or #
+type segment struct {
+	field    string
+	selector string // "", "*", an index or Field=value
+	length   bool
+}
+
+func parsePath(p string) ([]segment, error) {
+	if p == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var segments []segment
+	for len(p) > 0 {
+		end := strings.IndexAny(p, ".[")
+		if end < 0 {
+			end = len(p)
+		}
+		s := segment{field: p[:end]}
+		p = p[end:]
+
+		if strings.HasPrefix(p, "[") {
+			closing := strings.Index(p, "]")
+			if closing < 0 {
+				return nil, fmt.Errorf("unclosed [ in path")
+			}
+			s.selector = p[1:closing]
+			if s.selector == "" {
+				return nil, fmt.Errorf("empty [] in path")
+			}
+			p = p[closing+1:]
+		}
+		if s.field == "#" && s.selector == "" {
+			s = segment{length: true}
+		} else if s.field == "" {
+			return nil, fmt.Errorf("empty field in path")
+		}
+		segments = append(segments, s)
+
+		if strings.HasPrefix(p, ".") {
+			p = p[1:]
+			if p == "" {
+				return nil, fmt.Errorf("path ends with .")
+			}
+		} else if p != "" {
+			return nil, fmt.Errorf("unexpected %q in path", p)
+		}
+	}
+	return segments, nil
+}
+
+// resolved represents a value found for a path, value is invalid if the path does not exist
+type resolved struct {
+	path  string
+	value reflect.Value
+}
+
+// resolve returns every value of v matching segments, prefix is the path of v
+func resolve(v reflect.Value, segments []segment, prefix string) []resolved {
+	if len(segments) == 0 {
+		return []resolved{{path: prefix, value: v}}
+	}
+
+	s := segments[0]
+	missing := []resolved{{path: joinPath(prefix, pathString(segments))}}
+	if !v.IsValid() {
+		return missing
+	}
+
+	if s.length {
+		switch v.Kind() {
+		case reflect.Slice, reflect.Map, reflect.String:
+			return resolve(reflect.ValueOf(v.Len()), segments[1:], joinPath(prefix, "#"))
+		}
+		return missing
+	}
+
+	var field reflect.Value
+	switch v.Kind() {
+	case reflect.Struct:
+		if sf, ok := v.Type().FieldByName(s.field); ok && sf.PkgPath == "" {
+			field = v.FieldByIndex(sf.Index)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String {
+			field = v.MapIndex(reflect.ValueOf(s.field).Convert(v.Type().Key()))
+		}
+	}
+	if !field.IsValid() {
+		return missing
+	}
+	path := joinPath(prefix, s.field)
+
+	if s.selector == "" {
+		return resolve(field, segments[1:], path)
+	}
+	if field.Kind() != reflect.Slice {
+		return missing
+	}
+
+	if i, err := strconv.Atoi(s.selector); err == nil {
+		if i < 0 || i >= field.Len() {
+			return missing
+		}
+		return resolve(field.Index(i), segments[1:], fmt.Sprintf("%s[%d]", path, i))
+	}
+
+	var result []resolved
+	for i := 0; i < field.Len(); i++ {
+		if s.selector != "*" && !selected(field.Index(i), s.selector) {
+			continue
+		}
+		result = append(result, resolve(field.Index(i), segments[1:], fmt.Sprintf("%s[%d]", path, i))...)
+	}
+	if len(result) == 0 {
+		return missing
+	}
+	return result
+}
+
+// selected reports if v matches filter Field=value
+func selected(v reflect.Value, filter string) bool {
+	idx := strings.Index(filter, "=")
+	if idx < 0 {
+		return false
+	}
+	segments, err := parsePath(filter[:idx])
+	if err != nil {
+		return false
+	}
+	for _, r := range resolve(v, segments, "") {
+		if !r.value.IsValid() {
+			return false
+		}
+		if ok, err := compare(r.value, OpEqual, filter[idx+1:], 0); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(prefix, p string) string {
+	if prefix == "" {
+		return p
+	}
+	return prefix + "." + p
+}
+
+func pathString(segments []segment) string {
+	parts := make([]string, 0, len(segments))
+	for _, s := range segments {
+		switch {
+		case s.length:
+			parts = append(parts, "#")
+		case s.selector != "":
+			parts = append(parts, s.field+"["+s.selector+"]")
+		default:
+			parts = append(parts, s.field)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func (r Rule) validate(info mediainfo.Info) []Violation {
+	segments, err := parsePath(r.Path)
+	if err != nil {
+		return []Violation{r.violation(r.Path, nil)}
+	}
+
+	var violations []Violation
+	for _, res := range resolve(reflect.ValueOf(info), segments, "") {
+		if !res.value.IsValid() {
+			violations = append(violations, r.violation(res.path, nil))
+			continue
+		}
+		actual := res.value.Interface()
+		if ok, err := compare(res.value, r.Op, r.Value, r.Tolerance); err != nil || !ok {
+			violations = append(violations, r.violation(res.path, actual))
+		}
+	}
+	return violations
+}
+
+func (r Rule) violation(path string, actual interface{}) Violation {
+	return Violation{
+		Rule:     r.Name,
+		Path:     path,
+		Op:       r.Op,
+		Expected: r.Value,
+		Actual:   actual,
+		Severity: r.Severity,
+	}
+}
+
+// compare compares actual with expected using op
+func compare(actual reflect.Value, op Operator, expected interface{}, tolerance float64) (bool, error) {
+	switch op {
+	case OpIn, OpNotIn:
+		list, ok := expected.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s needs a list value", op)
+		}
+		for _, e := range list {
+			if ok, err := compare(actual, OpEqual, e, tolerance); err == nil && ok {
+				return op == OpIn, nil
+			}
+		}
+		return op == OpNotIn, nil
+	case OpMatch:
+		re, err := regexp.Compile(fmt.Sprint(expected))
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(fmt.Sprint(actual.Interface())), nil
+	}
+
+	switch actual.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareFloat(float64(actual.Int()), op, expected, tolerance, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareFloat(float64(actual.Uint()), op, expected, tolerance, 64)
+	case reflect.Float32:
+		return compareFloat(actual.Float(), op, expected, tolerance, 32)
+	case reflect.Float64:
+		return compareFloat(actual.Float(), op, expected, tolerance, 64)
+	case reflect.Bool:
+		e, err := toBool(expected)
+		if err != nil {
+			return false, err
+		}
+		return compareOrdered(boolToInt(actual.Bool()), boolToInt(e), op)
+	}
+
+	if t, ok := actual.Interface().(time.Time); ok {
+		e, err := toTime(expected)
+		if err != nil {
+			return false, err
+		}
+		return compareOrdered(t.Sub(e).Seconds(), 0.0, op)
+	}
+
+	a, e := fmt.Sprint(actual.Interface()), fmt.Sprint(expected)
+	return compareOrdered(strings.Compare(a, e), 0, op)
+}
+
+// compareFloat compares numbers, expected is rounded to the precision (bits) of actual
+func compareFloat(actual float64, op Operator, expected interface{}, tolerance float64, bits int) (bool, error) {
+	e, err := toFloat(expected)
+	if err != nil {
+		return false, err
+	}
+	if bits == 32 {
+		e = float64(float32(e))
+	}
+	if math.Abs(actual-e) <= tolerance {
+		actual = e
+	}
+	return compareOrdered(actual, e, op)
+}
+
+func compareOrdered(a, b interface{}, op Operator) (bool, error) {
+	var c int
+	switch a := a.(type) {
+	case int:
+		b := b.(int)
+		c = a - b
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			c = -1
+		case a > b:
+			c = 1
+		}
+	}
+
+	switch op {
+	case OpEqual:
+		return c == 0, nil
+	case OpNotEqual:
+		return c != 0, nil
+	case OpLess:
+		return c < 0, nil
+	case OpLessOrEqual:
+		return c <= 0, nil
+	case OpGreater:
+		return c > 0, nil
+	case OpGreaterOrEqual:
+		return c >= 0, nil
+	}
+	return false, fmt.Errorf("unknown operator %q", op)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "yes", "1":
+			return true, nil
+		case "false", "no", "0":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%v is not a boolean", v)
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", v)
+	}
+	return time.Time{}, fmt.Errorf("%v is not a time", v)
+}
@@ -0,0 +1,171 @@
+// Package spec checks mediainfo.Info values against delivery specifications
+// written as rules in YAML or JSON.
+//
+// A rule compares the value at Path with Value using Op:
+//
+//	rules:
+//	  - path: VideoTracks[0].Format
+//	    op: eq
+//	    value: HEVC
+//	  - path: VideoTracks[0].FrameRate
+//	    op: eq
+//	    value: 23.976
+//	    tolerance: 0.001
+//	  - path: AudioTracks.#
+//	    op: eq
+//	    value: 2
+//	  - path: AudioTracks[Default=true].Language
+//	    op: eq
+//	    value: en
+//	    severity: warning
+//
+// Paths use the Go field names of mediainfo.Info. A slice element is selected
+// by index ([0]), by a field value ([Default=true]) or all of them ([*]);
+// "#" is the length of a slice.
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/prcoito/mediainfo"
+	"gopkg.in/yaml.v3"
+)
+
+// Operator represents a comparison operator of a Rule
+type Operator string
+
+// Operators supported in rules
+const (
+	OpEqual          Operator = "eq"
+	OpNotEqual       Operator = "ne"
+	OpLess           Operator = "lt"
+	OpLessOrEqual    Operator = "le"
+	OpGreater        Operator = "gt"
+	OpGreaterOrEqual Operator = "ge"
+	OpIn             Operator = "in"     // Value is a list
+	OpNotIn          Operator = "not_in" // Value is a list
+	OpMatch          Operator = "match"  // Value is a regular expression
+)
+
+// Severity represents how important a Violation is
+type Severity string
+
+// Severities of a Rule, SeverityError is the default
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Rule represents a single check of a Spec
+type Rule struct {
+	Name      string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Path      string      `json:"path" yaml:"path"`
+	Op        Operator    `json:"op" yaml:"op"`
+	Value     interface{} `json:"value" yaml:"value"`
+	Tolerance float64     `json:"tolerance,omitempty" yaml:"tolerance,omitempty"` // for numeric eq/ne
+	Severity  Severity    `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+// Spec represents a delivery specification
+type Spec struct {
+	Name  string `json:"name,omitempty" yaml:"name,omitempty"`
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Violation represents a rule not satisfied by an Info
+type Violation struct {
+	Rule     string // Rule.Name, if any
+	Path     string // path of the value, with wildcards and filters resolved
+	Op       Operator
+	Expected interface{}
+	Actual   interface{} // nil if the path does not exist
+	Severity Severity
+}
+
+func (v Violation) String() string {
+	name := ""
+	if v.Rule != "" {
+		name = " (" + v.Rule + ")"
+	}
+	if v.Actual == nil {
+		return fmt.Sprintf("%s: %s%s: missing, expected %s %v", v.Severity, v.Path, name, v.Op, v.Expected)
+	}
+	return fmt.Sprintf("%s: %s%s: got %v, expected %s %v", v.Severity, v.Path, name, v.Actual, v.Op, v.Expected)
+}
+
+// Load loads a Spec from a .json, .yaml or .yml file
+func Load(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseJSON(data)
+	}
+	return ParseYAML(data)
+}
+
+// ParseJSON parses a Spec in JSON
+func ParseJSON(data []byte) (*Spec, error) {
+	s := &Spec{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, s.check()
+}
+
+// ParseYAML parses a Spec in YAML
+func ParseYAML(data []byte) (*Spec, error) {
+	s := &Spec{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, s.check()
+}
+
+// check validates the rules and sets their defaults
+func (s *Spec) check() error {
+	for i := range s.Rules {
+		r := &s.Rules[i]
+		if r.Severity == "" {
+			r.Severity = SeverityError
+		}
+		if _, err := parsePath(r.Path); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		switch r.Op {
+		case OpEqual, OpNotEqual, OpLess, OpLessOrEqual, OpGreater, OpGreaterOrEqual, OpMatch:
+		case OpIn, OpNotIn:
+			if _, ok := r.Value.([]interface{}); !ok {
+				return fmt.Errorf("rule %d: %s needs a list value", i+1, r.Op)
+			}
+		default:
+			return fmt.Errorf("rule %d: unknown operator %q", i+1, r.Op)
+		}
+	}
+	return nil
+}
+
+// Validate returns the violations of info to s, nil if info satisfies every rule
+func (s *Spec) Validate(info mediainfo.Info) []Violation {
+	var violations []Violation
+	for _, r := range s.Rules {
+		violations = append(violations, r.validate(info)...)
+	}
+	return violations
+}
+
+// HasErrors reports whether violations contains one with SeverityError
+func HasErrors(violations []Violation) bool {
+	for _, v := range violations {
+		if v.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package spec
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prcoito/mediainfo"
+)
+
+var uhdSpec = `
+name: UHD HEVC delivery
+rules:
+  - path: VideoTracks[0].Format
+    op: eq
+    value: HEVC
+  - path: VideoTracks[0].FormatProfile
+    op: eq
+    value: Main 10
+  - path: VideoTracks[0].Width
+    op: eq
+    value: 3840
+  - path: VideoTracks[0].Height
+    op: eq
+    value: 2160
+  - path: VideoTracks[0].FrameRate
+    op: eq
+    value: 23.976
+  - path: VideoTracks[0].FrameRateMode
+    op: eq
+    value: CFR
+  - path: AudioTracks.#
+    op: eq
+    value: 2
+  - path: AudioTracks[*].Channels
+    op: in
+    value: [6, 2]
+  - name: english default
+    path: AudioTracks[Default=true].Language
+    op: eq
+    value: en
+    severity: warning
+  - path: General.Duration
+    op: ge
+    value: 60
+`
+
+func uhdInfo() mediainfo.Info {
+	return mediainfo.Info{
+		General: mediainfo.General{Duration: 4086.355},
+		VideoTracks: []mediainfo.Video{{
+			Format:        "HEVC",
+			FormatProfile: "Main 10",
+			Width:         3840,
+			Height:        2160,
+			FrameRate:     23.976,
+			FrameRateMode: "CFR",
+		}},
+		AudioTracks: []mediainfo.Audio{
+			{Channels: 6, Language: "en", Default: true},
+			{Channels: 2, Language: "en"},
+		},
+	}
+}
+
+func TestSpec_Validate(t *testing.T) {
+	s, err := ParseYAML([]byte(uhdSpec))
+	if err != nil {
+		t.Fatalf("ParseYAML() error = %v", err)
+	}
+
+	if got := s.Validate(uhdInfo()); got != nil {
+		t.Errorf("Validate() = %v, want no violations", got)
+	}
+
+	info := uhdInfo()
+	info.VideoTracks[0].Width = 1920
+	info.AudioTracks[1].Channels = 8
+	info.AudioTracks[0].Default = false
+	info.AudioTracks = append(info.AudioTracks, mediainfo.Audio{Channels: 2, Language: "fr", Default: true})
+
+	want := []Violation{
+		{Path: "VideoTracks[0].Width", Op: OpEqual, Expected: 3840, Actual: uint(1920), Severity: SeverityError},
+		{Path: "AudioTracks.#", Op: OpEqual, Expected: 2, Actual: 3, Severity: SeverityError},
+		{Path: "AudioTracks[1].Channels", Op: OpIn, Expected: []interface{}{6, 2}, Actual: uint(8), Severity: SeverityError},
+		{Rule: "english default", Path: "AudioTracks[2].Language", Op: OpEqual, Expected: "en", Actual: "fr", Severity: SeverityWarning},
+	}
+	got := s.Validate(info)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate()\nGot \n%+v\nWant\n%+v", got, want)
+	}
+	if !HasErrors(got) {
+		t.Errorf("HasErrors() = false, want true")
+	}
+}
+
+func TestSpec_ValidateMissing(t *testing.T) {
+	s, err := ParseJSON([]byte(`{"rules":[{"path":"VideoTracks[0].Format","op":"eq","value":"HEVC"},{"path":"AudioTracks[Default=true].Language","op":"eq","value":"en"}]}`))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	got := s.Validate(mediainfo.Info{})
+	want := []Violation{
+		{Path: "VideoTracks[0].Format", Op: OpEqual, Expected: "HEVC", Severity: SeverityError},
+		{Path: "AudioTracks[Default=true].Language", Op: OpEqual, Expected: "en", Severity: SeverityError},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate()\nGot \n%+v\nWant\n%+v", got, want)
+	}
+}
+
+func TestSpec_ValidateDate(t *testing.T) {
+	info := mediainfo.Info{General: mediainfo.General{EncodedDate: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}}
+	tests := []struct {
+		op    Operator
+		value interface{}
+		want  bool
+	}{
+		{OpEqual, "2024-03-01T12:00:00Z", true},
+		{OpEqual, "2024-03-01", false},
+		{OpNotEqual, "2024-03-01", true},
+		{OpLess, "2024-03-02", true},
+		{OpLess, "2024-03-01", false},
+		{OpLessOrEqual, "2024-03-01T12:00:00Z", true},
+		{OpLessOrEqual, "2024-03-01", false},
+		{OpGreater, "2024-03-01", true},
+		{OpGreater, "2024-03-02", false},
+		{OpGreaterOrEqual, "2024-03-01T12:00:00Z", true},
+		{OpGreaterOrEqual, "2024-03-02", false},
+		{OpIn, []interface{}{"2023-01-01", "2024-03-01T12:00:00Z"}, true},
+		{OpNotIn, []interface{}{"2023-01-01", "2024-03-01T12:00:00Z"}, false},
+		{OpMatch, "^2024-03-01", true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.op), func(t *testing.T) {
+			s := &Spec{Rules: []Rule{{Path: "General.EncodedDate", Op: tt.op, Value: tt.value}}}
+			if err := s.check(); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Validate(info) == nil; got != tt.want {
+				t.Errorf("Validate() %s %v passes = %v, want %v", tt.op, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{name: "unknown operator", spec: `{"rules":[{"path":"General.Format","op":"like","value":"x"}]}`},
+		{name: "in without list", spec: `{"rules":[{"path":"General.Format","op":"in","value":"x"}]}`},
+		{name: "unclosed selector", spec: `{"rules":[{"path":"VideoTracks[0.Format","op":"eq","value":"x"}]}`},
+		{name: "trailing dot", spec: `{"rules":[{"path":"General.","op":"eq","value":"x"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseJSON([]byte(tt.spec)); err == nil {
+				t.Errorf("ParseJSON() expected error")
+			}
+		})
+	}
+}
+
+func Test_compare(t *testing.T) {
+	tests := []struct {
+		name      string
+		actual    interface{}
+		op        Operator
+		expected  interface{}
+		tolerance float64
+		want      bool
+	}{
+		{name: "float32 equal", actual: float32(23.976), op: OpEqual, expected: 23.976, want: true},
+		{name: "float tolerance", actual: float32(23.976), op: OpEqual, expected: 24, tolerance: 0.03, want: true},
+		{name: "float out of tolerance", actual: float32(25), op: OpEqual, expected: 24, tolerance: 0.03, want: false},
+		{name: "uint less", actual: uint(1080), op: OpLess, expected: 2160, want: true},
+		{name: "bool string", actual: true, op: OpEqual, expected: "yes", want: true},
+		{name: "string not in", actual: "AAC", op: OpNotIn, expected: []interface{}{"MP3", "FLAC"}, want: true},
+		{name: "regexp", actual: "Main 10", op: OpMatch, expected: `^Main( 10)?$`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compare(reflect.ValueOf(tt.actual), tt.op, tt.expected, tt.tolerance)
+			if err != nil {
+				t.Fatalf("compare() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("compare() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package mediainfo
+
+import "strings"
+
+// Tags represents the metadata tags (music, TV...) of the General track or of a track.
+// Tags not mapped to a field are present in Custom.
+type Tags struct {
+	Album          string
+	AlbumPerformer string
+	Performer      string
+	Composer       string
+	Publisher      string
+	TrackName      string
+	TrackPosition  uint
+	TrackTotal     uint
+	Part           string // e.g. episode or disc title
+	PartPosition   uint   // e.g. episode or disc number
+	PartTotal      uint
+	Collection     string // e.g. TV show name
+	Season         string
+	SeasonPosition uint
+	SeasonTotal    uint
+	Genre          string
+	ContentType    string
+	RecordedDate   string // free form as tagged, e.g. "2004" or "2004-05-01"
+	Comment        string
+	Description    string
+	Copyright      string
+	Custom         map[string]string
+}
+
+// tagFields are the tag fields present in libmediainfo output
+type tagFields struct {
+	Album               string
+	AlbumPerformer      string `json:"Album_Performer"`
+	Performer           string
+	Composer            string
+	Publisher           string
+	Track               string
+	TrackPosition       string `json:"Track_Position"`
+	TrackPositionTotal  string `json:"Track_Position_Total"`
+	Part                string
+	PartPosition        string `json:"Part_Position"`
+	PartPositionTotal   string `json:"Part_Position_Total"`
+	Collection          string
+	Season              string
+	SeasonPosition      string `json:"Season_Position"`
+	SeasonPositionTotal string `json:"Season_Position_Total"`
+	Genre               string
+	ContentType         string
+	RecordedDate        string `json:"Recorded_Date"`
+	Comment             string
+	Description         string
+	Copyright           string
+}
+
+// newTags returns the tags of track
+func newTags(t track) Tags {
+	tags := Tags{
+		Album:          t.Album,
+		AlbumPerformer: t.AlbumPerformer,
+		Performer:      t.Performer,
+		Composer:       t.Composer,
+		Publisher:      t.Publisher,
+		TrackName:      t.Track,
+		TrackPosition:  toUint(t.TrackPosition),
+		TrackTotal:     toUint(t.TrackPositionTotal),
+		Part:           t.Part,
+		PartPosition:   toUint(t.PartPosition),
+		PartTotal:      toUint(t.PartPositionTotal),
+		Collection:     t.Collection,
+		Season:         t.Season,
+		SeasonPosition: toUint(t.SeasonPosition),
+		SeasonTotal:    toUint(t.SeasonPositionTotal),
+		Genre:          t.Genre,
+		ContentType:    t.ContentType,
+		RecordedDate:   t.RecordedDate,
+		Comment:        t.Comment,
+		Description:    t.Description,
+		Copyright:      t.Copyright,
+	}
+
+	for k, v := range t.Extra {
+		if strings.HasPrefix(k, "_") {
+			// menu entries
+			continue
+		}
+		if tags.Custom == nil {
+			tags.Custom = make(map[string]string)
+		}
+		tags.Custom[k] = v
+	}
+	return tags
+}
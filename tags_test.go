@@ -0,0 +1,62 @@
+package mediainfo
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_newTags(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want Tags
+	}{
+		{
+			name: "music",
+			json: `{"@type":"General","Album":"Album name","Album_Performer":"Various","Performer":"Artist","Track":"Song",` +
+				`"Track_Position":"3","Track_Position_Total":"12","Part_Position":"1","Genre":"Rock","Recorded_Date":"2004",` +
+				`"extra":{"ISRC":"USRC17607839","_00_00_00_000":"Chapter 1"}}`,
+			want: Tags{
+				Album:          "Album name",
+				AlbumPerformer: "Various",
+				Performer:      "Artist",
+				TrackName:      "Song",
+				TrackPosition:  3,
+				TrackTotal:     12,
+				PartPosition:   1,
+				Genre:          "Rock",
+				RecordedDate:   "2004",
+				Custom:         map[string]string{"ISRC": "USRC17607839"},
+			},
+		},
+		{
+			name: "tv show",
+			json: `{"@type":"General","Collection":"Show","Season_Position":"2","Part":"Pilot","Part_Position":"1","ContentType":"TV Show","Comment":"c","Copyright":"(c)"}`,
+			want: Tags{
+				Collection:     "Show",
+				SeasonPosition: 2,
+				Part:           "Pilot",
+				PartPosition:   1,
+				ContentType:    "TV Show",
+				Comment:        "c",
+				Copyright:      "(c)",
+			},
+		},
+		{
+			name: "no tags",
+			json: `{"@type":"Audio","Format":"AAC"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := track{}
+			if err := json.Unmarshal([]byte(tt.json), &tr); err != nil {
+				t.Fatal(err)
+			}
+			if got := newTags(tr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newTags() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,8 @@ type Info struct {
 	AudioTracks []Audio
 	TextTracks  []Text
 	MenuTracks  []Menu
+	Programs    []Program // MPEG-TS programs (services)
+	ScanMode    ScanMode  // depth used to analyze the file, see Estimated
 }
 
 // General represents the general track information present in Info
@@ -29,12 +31,14 @@ type General struct {
 	FileExtension         string
 	Format                string
 	FormatVersion         string
+	FormatProfile         string // e.g. "OpenDML" for AVI files over 1 GiB
 	FileSize              uint
 	Duration              float32
 	OverallBitRate        float32
 	FrameRate             float32
 	FrameCount            uint
 	IsStreamable          bool
+	NotInterleaved        bool // AVI with audio and video stored far apart, playback from slow media may stutter
 	EncodedDate           time.Time
 	FileCreatedDate       time.Time
 	FileModifiedDate      time.Time
@@ -43,42 +47,50 @@ type General struct {
 	EncodedLibraryVersion string
 	Title                 string
 	CompleteName          string
+	Tags                  Tags
 }
 
 // Video represents a video track information present in Info
 type Video struct {
-	StreamOrder            uint
-	ID                     uint
-	UniqueID               string
-	Format                 string
-	FormatProfile          string
-	FormatLevel            string
-	FormatTier             string
-	CodecID                string
-	Duration               float32
-	BitRate                float32
-	Width                  uint
-	Height                 uint
-	SampledWidth           uint
-	SampledHeight          uint
-	PixelAspectRatio       float32
-	DisplayAspectRatio     float32
-	FrameRateMode          string
-	FrameRate              float32
-	FrameCount             uint
-	ColorSpace             string
-	ChromaSubsampling      string
-	BitDepth               uint
-	StreamSize             uint
-	StreamSizeProportion   float32
-	EncodedLibrary         string
-	EncodedLibraryName     string
-	EncodedLibraryVersion  string
-	EncodedLibrarySettings string
-	Default                bool
-	Forced                 bool
-	B3D                    bool
-	Title                  string
+	StreamOrder             uint
+	ID                      uint
+	UniqueID                string
+	Format                  string
+	FormatProfile           string
+	FormatLevel             string
+	FormatTier              string
+	CodecID                 string
+	Duration                float32
+	BitRate                 float32
+	Width                   uint
+	Height                  uint
+	SampledWidth            uint
+	SampledHeight           uint
+	ActiveWidth             uint // width of the picture without the black bars, if known
+	ActiveHeight            uint // height of the picture without the black bars, if known
+	PixelAspectRatio        float32
+	DisplayAspectRatio      float32
+	FrameRateMode           string
+	FrameRate               float32
+	FrameCount              uint
+	ColorSpace              string
+	ChromaSubsampling       string
+	BitDepth                uint
+	HDRFormat               string // e.g. "Dolby Vision", "SMPTE ST 2086" (HDR10), empty for SDR
+	ColorPrimaries          string // e.g. "BT.709", "BT.2020"
+	TransferCharacteristics string // e.g. "BT.709", "PQ", "HLG"
+	MatrixCoefficients      string
+	StreamSize              uint
+	StreamSizeProportion    float32
+	EncodedLibrary          string
+	EncodedLibraryName      string
+	EncodedLibraryVersion   string
+	EncodedLibrarySettings  string
+	Default                 bool
+	Forced                  bool
+	B3D                     bool
+	Title                   string
+	Tags                    Tags
 }
 
 // Audio represents a audio track information present in Info
@@ -88,6 +100,7 @@ type Audio struct {
 	UniqueID                 string
 	Format                   string
 	FormatCommercial         string
+	FormatProfile            string
 	FormatAdditionalFeatures string
 	CodecID                  string
 	Duration                 float32
@@ -98,6 +111,7 @@ type Audio struct {
 	SamplesPerFrame          uint
 	SamplingRate             uint
 	SamplingCount            uint
+	BitDepth                 uint
 	FrameRate                float32
 	FrameCount               uint
 	CompressionMode          string
@@ -107,6 +121,7 @@ type Audio struct {
 	Default                  bool
 	Forced                   bool
 	Title                    string
+	Tags                     Tags
 }
 
 // Text represents a text track (subtitles) information present in Info
@@ -126,23 +141,45 @@ type Text struct {
 	Default      bool
 	Forced       bool
 	Title        string
+	Tags         Tags
 }
 
-// Menu represents the Menu track (also known as Chapter) present in Info
+// Menu represents the Menu track (also known as Chapter) present in Info.
+// Each chapter edition is represented by its own Menu.
 type Menu struct {
 	Order    uint
+	Edition  uint // zero based index of the edition in the file
+	Ordered  bool // edition is ordered (only available from Matroska XML)
+	Hidden   bool // edition is hidden (only available from Matroska XML)
+	Default  bool // edition is the default one (only available from Matroska XML)
 	Entries  []Entry
 	Duration float32
 }
 
+// Program represents a program (service) of a MPEG transport stream
+type Program struct {
+	Number          uint // program_number, also the DVB service_id
+	PMTPID          uint
+	PCRPID          uint // only available from InformTS
+	ServiceName     string
+	ServiceProvider string
+	PIDs            []uint // PIDs of the elementary streams of the program
+}
+
+// Language represents a language code as reported by libmediainfo (ISO 639 or BCP 47)
+type Language string
+
 // Entry represents an entry in Menu.Entries
 type Entry struct {
 	StartTime    float32
 	StartTimeStr string
 	EndTime      float32
 	EndTimeStr   string
-	Title        string
+	Title        string // title in Language, the first one present
 	Language     string
+	Titles       map[Language]string // all titles of the entry by language
+	Hidden       bool                // only available from Matroska XML
+	Entries      []Entry             // nested chapters (only available from Matroska XML)
 }
 
 // track struct represent a media track
@@ -164,6 +201,7 @@ type track struct {
 	FrameCount            string
 	StreamSize            string
 	IsStreamable          string
+	Interleaved           string
 	Title                 string
 	Movie                 string
 	FileCreatedDate       string      `json:"File_Created_Date"`
@@ -184,20 +222,26 @@ type track struct {
 	Height             string
 	SampledWidth       string `json:"Sampled_Width"`
 	SampledHeight      string `json:"Sampled_Height"`
+	ActiveWidth        string `json:"Active_Width"`
+	ActiveHeight       string `json:"Active_Height"`
 	PixelAspectRatio   string
 	DisplayAspectRatio string
 	FrameRateMode      string `json:"FrameRate_Mode"`
 	MultiViewCount     string `json:"Multi_View_Count"`
 
-	ColorSpace             string
-	ChromaSubsampling      string
-	BitDepth               string
-	Delay                  string
-	EncodedLibraryName     string `json:"Encoded_Library_Name"`
-	EncodedLibrarySettings string `json:"Encoded_Library_Settings"`
-	Language               string
-	Default                string
-	Forced                 string
+	ColorSpace              string
+	ChromaSubsampling       string
+	BitDepth                string
+	HDRFormat               string `json:"HDR_Format"`
+	ColorPrimaries          string `json:"colour_primaries"`
+	TransferCharacteristics string `json:"transfer_characteristics"`
+	MatrixCoefficients      string `json:"matrix_coefficients"`
+	Delay                   string
+	EncodedLibraryName      string `json:"Encoded_Library_Name"`
+	EncodedLibrarySettings  string `json:"Encoded_Library_Settings"`
+	Language                string
+	Default                 string
+	Forced                  string
 
 	FormatAdditionalFeatures string `json:"Format_AdditionalFeatures"`
 	BitRate                  string
@@ -211,8 +255,20 @@ type track struct {
 	DelaySource              string `json:"Delay_Source"`
 	StreamSizeProportion     string `json:"StreamSize_Proportion"`
 
-	ElementCount string
-	TypeOrder    string `json:"@typeorder"`
+	ElementCount    string
+	MenuID          string
+	ServiceName     string
+	ServiceProvider string
+	List            string
+	TypeOrder       string `json:"@typeorder"`
+
+	Cover            string
+	CoverType        string `json:"Cover_Type"`
+	CoverMime        string `json:"Cover_Mime"`
+	CoverDescription string `json:"Cover_Description"`
+	CoverData        string `json:"Cover_Data"`
+
+	tagFields
 
 	Extra map[string]string
 }
@@ -0,0 +1,256 @@
+package mediainfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// httpBlockSize is the size of the blocks fetched by range requests
+	httpBlockSize = 256 << 10
+	// httpCacheBlocks is the number of blocks kept by an httpReaderAt
+	httpCacheBlocks = 64
+)
+
+// InformURL returns the Media details (struct Info) of the file at url (http or https) without
+// downloading it: the parts libmediainfo reads are fetched with HTTP range requests and cached.
+// The server must support range requests (ErrRangeNotSupported otherwise) and the file must not
+// change during the analysis. client is http.DefaultClient if nil.
+func InformURL(ctx context.Context, url string, client *http.Client, opts ...Option) (Info, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r, err := newHTTPReaderAt(ctx, client, url)
+	if err != nil {
+		return Info{}, err
+	}
+
+	info, err := InformReaderAt(r, r.size, opts...)
+	if errors.Is(err, ErrUnsupportedFormat) {
+		return Info{}, &OpenError{Path: url, Err: ErrUnsupportedFormat}
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	info.General.CompleteName = url
+	return info, nil
+}
+
+// httpReaderAt is an io.ReaderAt over HTTP range requests, caching the last fetched blocks
+type httpReaderAt struct {
+	ctx    context.Context
+	client *http.Client
+	url    string
+	size   int64
+	etag   string // to detect a change of the file between requests
+
+	mu       sync.Mutex
+	blocks   map[int64][]byte // by block index
+	lru      []int64          // block indexes, least recently used first
+	requests int
+}
+
+// newHTTPReaderAt returns a reader of url, fetching its first block to get its size
+func newHTTPReaderAt(ctx context.Context, client *http.Client, url string) (*httpReaderAt, error) {
+	r := &httpReaderAt{
+		ctx:    ctx,
+		client: client,
+		url:    url,
+		blocks: make(map[int64][]byte),
+	}
+	resp, err := r.get(0, httpBlockSize-1)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		// empty file
+		return nil, &OpenError{Path: url, Err: ErrUnsupportedFormat}
+	case http.StatusOK:
+		return nil, &OpenError{Path: url, Err: ErrRangeNotSupported}
+	default:
+		return nil, httpError(url, resp)
+	}
+
+	first, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+	if !ok || first != 0 {
+		// "*": unknown size
+		return nil, &OpenError{Path: url, Err: ErrRangeNotSupported}
+	}
+	r.size = size
+	r.etag = resp.Header.Get("ETag")
+
+	n := int64(httpBlockSize)
+	if n > r.size {
+		n = r.size
+	}
+	b, err := readBody(resp, n)
+	if err != nil {
+		return nil, err
+	}
+	r.put(0, b)
+	return r, nil
+}
+
+// parseContentRange returns the first byte and the size of a Content-Range header,
+// e.g. "bytes 0-262143/1048576"
+func parseContentRange(cr string) (first, size int64, ok bool) {
+	cr = strings.TrimPrefix(cr, "bytes ")
+	dash, slash := strings.IndexByte(cr, '-'), strings.LastIndexByte(cr, '/')
+	if dash < 0 || slash < dash {
+		return 0, 0, false
+	}
+	first, err := strconv.ParseInt(cr[:dash], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	size, err = strconv.ParseInt(cr[slash+1:], 10, 64)
+	if err != nil || size <= 0 || first >= size {
+		return 0, 0, false
+	}
+	return first, size, true
+}
+
+// readBody reads the n bytes of the body of resp, io.ErrUnexpectedEOF if it is shorter
+func readBody(resp *http.Response, n int64) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, n))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) != n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return b, nil
+}
+
+// get requests the bytes first to last (inclusive) of the file
+func (r *httpReaderAt) get(first, last int64) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(r.ctx)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", first, last))
+	if r.etag != "" && !isWeakETag(r.etag) {
+		// If-Match uses the strong comparison, weak ETags are compared by changed
+		req.Header.Set("If-Match", r.etag)
+	}
+	r.requests++
+	return r.client.Do(req)
+}
+
+// isWeakETag reports whether etag is a weak validator, e.g. W/"5d-1a2b"
+func isWeakETag(etag string) bool {
+	return strings.HasPrefix(etag, "W/")
+}
+
+// changed reports whether resp is of a file different from the one of the first response
+func (r *httpReaderAt) changed(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return true
+	}
+	etag := resp.Header.Get("ETag")
+	if r.etag == "" || etag == "" {
+		return false
+	}
+	// weak comparison
+	return strings.TrimPrefix(etag, "W/") != strings.TrimPrefix(r.etag, "W/")
+}
+
+// httpError returns the error of an unexpected response
+func httpError(url string, resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return &OpenError{Path: url, Err: os.ErrNotExist}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &OpenError{Path: url, Err: os.ErrPermission}
+	}
+	return &OpenError{Path: url, Err: fmt.Errorf("HTTP %s", resp.Status)}
+}
+
+// ReadAt implements io.ReaderAt
+func (r *httpReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= r.size {
+			return n, io.EOF
+		}
+		b, err := r.block(pos / httpBlockSize)
+		if err != nil {
+			return n, err
+		}
+		n += copy(p[n:], b[pos%httpBlockSize:])
+	}
+	return n, nil
+}
+
+// block returns the block i, from the cache or fetched
+func (r *httpReaderAt) block(i int64) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if b, ok := r.blocks[i]; ok {
+		r.touch(i)
+		return b, nil
+	}
+
+	first := i * httpBlockSize
+	last := first + httpBlockSize - 1
+	if last >= r.size {
+		last = r.size - 1
+	}
+	resp, err := r.get(first, last)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if r.changed(resp) {
+		return nil, &OpenError{Path: r.url, Err: fmt.Errorf("file changed during the analysis")}
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, httpError(r.url, resp)
+	}
+	if start, size, ok := parseContentRange(resp.Header.Get("Content-Range")); !ok || start != first || size != r.size {
+		return nil, &OpenError{Path: r.url, Err: fmt.Errorf("unexpected Content-Range %q", resp.Header.Get("Content-Range"))}
+	}
+	b, err := readBody(resp, last-first+1)
+	if err != nil {
+		return nil, err
+	}
+	r.put(i, b)
+	return b, nil
+}
+
+// put caches the block i, evicting the least recently used one when full
+func (r *httpReaderAt) put(i int64, b []byte) {
+	if len(r.lru) >= httpCacheBlocks {
+		delete(r.blocks, r.lru[0])
+		r.lru = r.lru[1:]
+	}
+	r.blocks[i] = b
+	r.lru = append(r.lru, i)
+}
+
+// touch marks the block i as the most recently used
+func (r *httpReaderAt) touch(i int64) {
+	for j, k := range r.lru {
+		if k == i {
+			r.lru = append(append(r.lru[:j:j], r.lru[j+1:]...), i)
+			return
+		}
+	}
+}
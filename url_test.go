@@ -0,0 +1,238 @@
+package mediainfo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// serveBytes returns a server of data with range requests, the ETag of every response is etag()
+func serveBytes(data []byte, etag func() string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag())
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(data))
+	}))
+}
+
+func Test_httpReaderAt(t *testing.T) {
+	data := make([]byte, 3*httpBlockSize+100)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	etag := `"v1"`
+	srv := serveBytes(data, func() string { return etag })
+	defer srv.Close()
+
+	r, err := newHTTPReaderAt(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatalf("newHTTPReaderAt() error = %v", err)
+	}
+	if r.size != int64(len(data)) {
+		t.Errorf("size = %d, want %d", r.size, len(data))
+	}
+
+	tests := []struct {
+		off, n int64
+	}{
+		{0, 100},
+		{httpBlockSize - 10, 20}, // across blocks
+		{3 * httpBlockSize, 100}, // last block
+		{10, 2*httpBlockSize + 10},
+	}
+	for _, tt := range tests {
+		p := make([]byte, tt.n)
+		if n, err := r.ReadAt(p, tt.off); n != len(p) || err != nil {
+			t.Errorf("ReadAt(%d, %d) = %d, %v", tt.off, tt.n, n, err)
+		}
+		if !bytes.Equal(p, data[tt.off:tt.off+tt.n]) {
+			t.Errorf("ReadAt(%d, %d) wrong data", tt.off, tt.n)
+		}
+	}
+	// one request per block, the blocks read again are cached
+	if r.requests != 4 {
+		t.Errorf("requests = %d, want 4", r.requests)
+	}
+
+	p := make([]byte, 200)
+	if n, err := r.ReadAt(p, int64(len(data))-100); n != 100 || err == nil {
+		t.Errorf("ReadAt() past the end = %d, %v, want 100, EOF", n, err)
+	}
+
+	// the native parsers read through the same io.ReaderAt
+	avi := testAVI(true)
+	aviSrv := serveBytes(avi, func() string { return `"avi"` })
+	defer aviSrv.Close()
+	ar, err := newHTTPReaderAt(context.Background(), aviSrv.Client(), aviSrv.URL)
+	if err != nil {
+		t.Fatalf("newHTTPReaderAt() error = %v", err)
+	}
+	if info, err := readAVI(ar, ar.size); err != nil || info.General.Format != "AVI" {
+		t.Errorf("readAVI() = %+v, %v", info.General, err)
+	}
+
+	// the file changes on the server
+	etag = `"v2"`
+	r.blocks = make(map[int64][]byte)
+	r.lru = nil
+	if _, err := r.ReadAt(p, 0); err == nil {
+		t.Errorf("ReadAt() of a changed file, want error")
+	}
+}
+
+func Test_httpReaderAt_cache(t *testing.T) {
+	data := make([]byte, (httpCacheBlocks+2)*httpBlockSize)
+	srv := serveBytes(data, func() string { return "" })
+	defer srv.Close()
+	r, err := newHTTPReaderAt(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 1)
+	for i := int64(0); i < httpCacheBlocks+2; i++ {
+		r.ReadAt(p, i*httpBlockSize)
+	}
+	if len(r.blocks) != httpCacheBlocks || len(r.lru) != httpCacheBlocks {
+		t.Errorf("cached %d blocks, want %d", len(r.blocks), httpCacheBlocks)
+	}
+	if _, ok := r.blocks[0]; ok {
+		t.Errorf("block 0 not evicted")
+	}
+}
+
+func Test_httpReaderAt_weakETag(t *testing.T) {
+	data := make([]byte, 2*httpBlockSize)
+	etag := `W/"v1"`
+	srv := serveBytes(data, func() string { return etag })
+	defer srv.Close()
+
+	r, err := newHTTPReaderAt(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatalf("newHTTPReaderAt() error = %v", err)
+	}
+	p := make([]byte, 10)
+	if _, err := r.ReadAt(p, httpBlockSize); err != nil {
+		t.Errorf("ReadAt() error = %v", err)
+	}
+
+	etag = `W/"v2"`
+	r.blocks = make(map[int64][]byte)
+	r.lru = nil
+	if _, err := r.ReadAt(p, httpBlockSize); err == nil {
+		t.Errorf("ReadAt() of a changed file, want error")
+	}
+}
+
+func Test_httpReaderAt_badRanges(t *testing.T) {
+	// the Content-Range and body of the response to a request of first
+	respond := func(first int64) (string, int) {
+		return "", 0
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var first int64
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &first)
+		cr, n := respond(first)
+		w.Header().Set("Content-Range", cr)
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(make([]byte, n))
+	}))
+	defer srv.Close()
+
+	// short first block
+	respond = func(first int64) (string, int) {
+		return "bytes 0-99/1000000", 100
+	}
+	if _, err := newHTTPReaderAt(context.Background(), srv.Client(), srv.URL); err != io.ErrUnexpectedEOF {
+		t.Errorf("newHTTPReaderAt() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	// wrong range of the second block
+	size := int64(2 * httpBlockSize)
+	respond = func(first int64) (string, int) {
+		return fmt.Sprintf("bytes 0-%d/%d", httpBlockSize-1, size), httpBlockSize
+	}
+	r, err := newHTTPReaderAt(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatalf("newHTTPReaderAt() error = %v", err)
+	}
+	p := make([]byte, 10)
+	if _, err := r.ReadAt(p, httpBlockSize); err == nil {
+		t.Errorf("ReadAt() of a wrong range, want error")
+	}
+}
+
+func TestInformURL_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/norange":
+			w.Write([]byte("no range support"))
+		case "/private":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path string
+		want error
+	}{
+		{"/norange", ErrRangeNotSupported},
+		{"/private", os.ErrPermission},
+		{"/missing", os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		_, err := InformURL(context.Background(), srv.URL+tt.path, nil)
+		var openErr *OpenError
+		if !errors.Is(err, tt.want) || !errors.As(err, &openErr) || openErr.Path != srv.URL+tt.path {
+			t.Errorf("InformURL(%s) error = %v, want %v", tt.path, err, tt.want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := InformURL(ctx, srv.URL+"/missing", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("InformURL() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestInformURL(t *testing.T) {
+	path := filepath.Join("testdata", "1_video_1_audio_1_menu.mkv")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := serveBytes(data, func() string { return `"mkv"` })
+	defer srv.Close()
+
+	if _, err := InformURL(context.Background(), srv.URL, srv.Client()); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("InformURL() error = %v, want %v", err, ErrNotLoaded)
+	}
+
+	if !Load() {
+		t.Fatalf("Failed to load dll/shared object")
+	}
+	defer Unload()
+
+	want, err := Inform(path)
+	if err != nil {
+		t.Fatalf("Inform() error = %v", err)
+	}
+	got, err := InformURL(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatalf("InformURL() error = %v", err)
+	}
+	if got.General.CompleteName != srv.URL || got.General.Format != want.General.Format ||
+		got.General.Duration != want.General.Duration || len(got.AudioTracks) != len(want.AudioTracks) {
+		t.Errorf("InformURL()\nGot \n%+v\nWant\n%+v", got, want)
+	}
+}
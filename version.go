@@ -0,0 +1,55 @@
+package mediainfo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Version represents a libmediainfo version (e.g. 20.09 or 21.03.1)
+type Version struct {
+	Major uint
+	Minor uint
+	Patch uint
+}
+
+// MinimumVersion is the oldest libmediainfo version supported by this module
+var MinimumVersion = Version{Major: 18, Minor: 3}
+
+func (v Version) String() string {
+	if v.Patch != 0 {
+		return fmt.Sprintf("%d.%02d.%d", v.Major, v.Minor, v.Patch)
+	}
+	return fmt.Sprintf("%d.%02d", v.Major, v.Minor)
+}
+
+// Less reports whether v is older than o
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+var versionRegexp = regexp.MustCompile(`v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseVersion parses the Info_Version option value, e.g. "MediaInfoLib - v20.09"
+func parseVersion(s string) (Version, error) {
+	match := versionRegexp.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, fmt.Errorf("invalid libmediainfo version: %q", s)
+	}
+
+	v := Version{}
+	major, _ := strconv.ParseUint(match[1], 10, 32)
+	minor, _ := strconv.ParseUint(match[2], 10, 32)
+	v.Major, v.Minor = uint(major), uint(minor)
+	if match[3] != "" {
+		patch, _ := strconv.ParseUint(match[3], 10, 32)
+		v.Patch = uint(patch)
+	}
+	return v, nil
+}
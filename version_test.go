@@ -0,0 +1,47 @@
+package mediainfo
+
+import (
+	"testing"
+)
+
+func Test_parseVersion(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    Version
+		wantErr bool
+	}{
+		{arg: "MediaInfoLib - v20.09", want: Version{Major: 20, Minor: 9}},
+		{arg: "MediaInfoLib - v18.03.1", want: Version{Major: 18, Minor: 3, Patch: 1}},
+		{arg: "MediaInfoLib", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := parseVersion(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion_Less(t *testing.T) {
+	tests := []struct {
+		v, o Version
+		want bool
+	}{
+		{v: Version{17, 12, 0}, o: MinimumVersion, want: true},
+		{v: Version{18, 2, 9}, o: MinimumVersion, want: true},
+		{v: MinimumVersion, o: MinimumVersion, want: false},
+		{v: Version{20, 9, 0}, o: MinimumVersion, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v.String(), func(t *testing.T) {
+			if got := tt.v.Less(tt.o); got != tt.want {
+				t.Errorf("Version.Less() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
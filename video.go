@@ -0,0 +1,204 @@
+package mediainfo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Resolution represents the resolution class of a video track
+type Resolution int
+
+const (
+	// ResolutionUnknown means the size of the track is unknown
+	ResolutionUnknown Resolution = iota
+	// ResolutionSD is below 1280x720
+	ResolutionSD
+	// ResolutionHD is 1280x720
+	ResolutionHD
+	// ResolutionFHD is 1920x1080
+	ResolutionFHD
+	// ResolutionUHD is 3840x2160
+	ResolutionUHD
+	// Resolution8K is 7680x4320
+	Resolution8K
+)
+
+func (r Resolution) String() string {
+	switch r {
+	case ResolutionUnknown:
+		return "Unknown"
+	case ResolutionSD:
+		return "SD"
+	case ResolutionHD:
+		return "HD"
+	case ResolutionFHD:
+		return "FHD"
+	case ResolutionUHD:
+		return "UHD"
+	case Resolution8K:
+		return "8K"
+	}
+	return fmt.Sprintf("Resolution(%d)", int(r))
+}
+
+// resolutions are the minimum width or height of each Resolution, highest first.
+// Either is enough so that cropped (e.g. 3840x1600) and pillarboxed (e.g. 1440x1080)
+// pictures get the class of their frame.
+var resolutions = []struct {
+	width, height uint
+	resolution    Resolution
+}{
+	{7680, 4320, Resolution8K},
+	{3840, 2160, ResolutionUHD},
+	{1920, 1080, ResolutionFHD},
+	{1280, 720, ResolutionHD},
+}
+
+// Resolution returns the resolution class of v
+func (v Video) Resolution() Resolution {
+	if v.Width == 0 || v.Height == 0 {
+		return ResolutionUnknown
+	}
+	for _, r := range resolutions {
+		if v.Width >= r.width || v.Height >= r.height {
+			return r.resolution
+		}
+	}
+	return ResolutionSD
+}
+
+// AspectRatio represents a standard display aspect ratio as a rational, e.g. 16/9
+type AspectRatio struct {
+	Num uint
+	Den uint
+}
+
+// Standard aspect ratios
+var (
+	AspectRatio1x1   = AspectRatio{1, 1}
+	AspectRatio5x4   = AspectRatio{5, 4}
+	AspectRatio4x3   = AspectRatio{4, 3}
+	AspectRatio3x2   = AspectRatio{3, 2}
+	AspectRatio16x10 = AspectRatio{16, 10}
+	AspectRatio16x9  = AspectRatio{16, 9}
+	AspectRatio185   = AspectRatio{185, 100}
+	AspectRatio2x1   = AspectRatio{2, 1}
+	AspectRatio220   = AspectRatio{220, 100}
+	AspectRatio235   = AspectRatio{235, 100}
+	AspectRatio239   = AspectRatio{239, 100}
+	AspectRatio240   = AspectRatio{240, 100}
+	AspectRatio21x9  = AspectRatio{64, 27} // marketed as 21:9
+)
+
+// aspectRatios are the standard aspect ratios, see NearestAspectRatio
+var aspectRatios = []AspectRatio{
+	AspectRatio1x1,
+	AspectRatio5x4,
+	AspectRatio4x3,
+	AspectRatio3x2,
+	AspectRatio16x10,
+	AspectRatio16x9,
+	AspectRatio185,
+	AspectRatio2x1,
+	AspectRatio220,
+	AspectRatio235,
+	AspectRatio239,
+	AspectRatio240,
+	AspectRatio21x9,
+}
+
+// Float returns the value of a, 0 if a is the zero value
+func (a AspectRatio) Float() float64 {
+	if a.Den == 0 {
+		return 0
+	}
+	return float64(a.Num) / float64(a.Den)
+}
+
+// Name returns the canonical name of a: "16:9" or "4:3" for ratios of small integers,
+// "2.39:1" for the cinema ones and "21:9" for 64/27
+func (a AspectRatio) Name() string {
+	switch {
+	case a.Den == 0:
+		return ""
+	case a == AspectRatio21x9:
+		return "21:9"
+	case a.Den == 100:
+		return fmt.Sprintf("%.2f:1", a.Float())
+	}
+	return fmt.Sprintf("%d:%d", a.Num, a.Den)
+}
+
+func (a AspectRatio) String() string {
+	return a.Name()
+}
+
+// NearestAspectRatio returns the standard aspect ratio nearest to ratio,
+// the zero value if ratio is not positive
+func NearestAspectRatio(ratio float64) AspectRatio {
+	if ratio <= 0 {
+		return AspectRatio{}
+	}
+	best := aspectRatios[0]
+	for _, a := range aspectRatios[1:] {
+		if math.Abs(ratio-a.Float()) < math.Abs(ratio-best.Float()) {
+			best = a
+		}
+	}
+	return best
+}
+
+// AspectRatio returns the standard display aspect ratio nearest to the one of v.
+// It is computed from the size and pixel aspect ratio if DisplayAspectRatio is unknown.
+func (v Video) AspectRatio() AspectRatio {
+	ratio := float64(v.DisplayAspectRatio)
+	if ratio == 0 && v.Height > 0 {
+		ratio = float64(v.Width) / float64(v.Height) * v.pixelAspectRatio()
+	}
+	return NearestAspectRatio(ratio)
+}
+
+// ActiveAspectRatio returns the standard aspect ratio nearest to the one of the picture
+// without the black bars, AspectRatio if the active size is unknown
+func (v Video) ActiveAspectRatio() AspectRatio {
+	if !v.IsLetterboxed() {
+		return v.AspectRatio()
+	}
+	w, h := v.activeSize()
+	return NearestAspectRatio(float64(w) / float64(h) * v.pixelAspectRatio())
+}
+
+// IsLetterboxed reports whether the picture has black bars (above and below or on the sides),
+// from the active size of v
+func (v Video) IsLetterboxed() bool {
+	w, h := v.activeSize()
+	return w < v.Width || h < v.Height
+}
+
+// IsAnamorphic reports whether the pixels of v are not square
+func (v Video) IsAnamorphic() bool {
+	return math.Abs(v.pixelAspectRatio()-1) > 0.01
+}
+
+// activeSize returns the size of the picture without the black bars, the frame size if unknown
+func (v Video) activeSize() (w, h uint) {
+	w, h = v.Width, v.Height
+	if v.ActiveWidth > 0 && v.ActiveWidth <= v.Width {
+		w = v.ActiveWidth
+	}
+	if v.ActiveHeight > 0 && v.ActiveHeight <= v.Height {
+		h = v.ActiveHeight
+	}
+	return
+}
+
+// pixelAspectRatio returns PixelAspectRatio, computed from DisplayAspectRatio if unknown, 1 by default
+func (v Video) pixelAspectRatio() float64 {
+	if v.PixelAspectRatio > 0 {
+		return float64(v.PixelAspectRatio)
+	}
+	if v.DisplayAspectRatio > 0 && v.Width > 0 && v.Height > 0 {
+		return float64(v.DisplayAspectRatio) * float64(v.Height) / float64(v.Width)
+	}
+	return 1
+}
@@ -0,0 +1,61 @@
+package mediainfo
+
+import "testing"
+
+func TestVideo_Resolution(t *testing.T) {
+	tests := []struct {
+		width, height uint
+		want          Resolution
+	}{
+		{0, 0, ResolutionUnknown},
+		{720, 576, ResolutionSD},
+		{1280, 720, ResolutionHD},
+		{1440, 1080, ResolutionFHD},
+		{1920, 800, ResolutionFHD},
+		{3840, 1600, ResolutionUHD},
+		{4096, 2160, ResolutionUHD},
+		{7680, 4320, Resolution8K},
+	}
+	for _, tt := range tests {
+		v := Video{Width: tt.width, Height: tt.height}
+		if got := v.Resolution(); got != tt.want {
+			t.Errorf("Video{%dx%d}.Resolution() = %v, want %v", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestVideo_AspectRatio(t *testing.T) {
+	tests := []struct {
+		name          string
+		video         Video
+		want          string
+		wantActive    string
+		wantAnamorph  bool
+		wantLetterbox bool
+	}{
+		{name: "16:9", video: Video{Width: 1920, Height: 1080, DisplayAspectRatio: 1.778}, want: "16:9", wantActive: "16:9"},
+		{name: "scope", video: Video{Width: 3840, Height: 1608, DisplayAspectRatio: 2.388}, want: "2.39:1", wantActive: "2.39:1"},
+		{name: "PAL anamorphic", video: Video{Width: 720, Height: 576, PixelAspectRatio: 1.422}, want: "16:9", wantActive: "16:9", wantAnamorph: true},
+		{name: "DAR only anamorphic", video: Video{Width: 1440, Height: 1080, DisplayAspectRatio: 1.778}, want: "16:9", wantActive: "16:9", wantAnamorph: true},
+		{name: "4:3", video: Video{Width: 640, Height: 480}, want: "4:3", wantActive: "4:3"},
+		{name: "letterboxed", video: Video{Width: 1920, Height: 1080, ActiveWidth: 1920, ActiveHeight: 800, DisplayAspectRatio: 1.778}, want: "16:9", wantActive: "2.40:1", wantLetterbox: true},
+		{name: "21:9", video: Video{Width: 2560, Height: 1080}, want: "21:9", wantActive: "21:9"},
+		{name: "unknown", video: Video{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.video.AspectRatio().Name(); got != tt.want {
+				t.Errorf("AspectRatio() = %q, want %q", got, tt.want)
+			}
+			if got := tt.video.ActiveAspectRatio().Name(); got != tt.wantActive {
+				t.Errorf("ActiveAspectRatio() = %q, want %q", got, tt.wantActive)
+			}
+			if got := tt.video.IsAnamorphic(); got != tt.wantAnamorph {
+				t.Errorf("IsAnamorphic() = %v, want %v", got, tt.wantAnamorph)
+			}
+			if got := tt.video.IsLetterboxed(); got != tt.wantLetterbox {
+				t.Errorf("IsLetterboxed() = %v, want %v", got, tt.wantLetterbox)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package mediainfo
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrWatchNotSupported is the error returned by NewWatcher on platforms without inotify
+var ErrWatchNotSupported = errors.New("watching files is not supported on this platform")
+
+// WatchOptions configures a Watcher
+type WatchOptions struct {
+	// Debounce is the delay after the last event of a file before its size is checked (default 500ms)
+	Debounce time.Duration
+	// StableInterval is how long the size and modification time of a file must not change
+	// before it is analyzed (default 2s)
+	StableInterval time.Duration
+	// Extensions are the extensions (e.g. ".mkv") of the files analyzed, every file if empty
+	Extensions []string
+	// Existing analyzes the files already present in the tree when the Watcher starts
+	Existing bool
+	// Options are the options of Inform
+	Options []Option
+}
+
+// WatchResult represents the analysis of a file by a Watcher
+type WatchResult struct {
+	Path string
+	Info Info
+	Err  error // error returned by Inform, e.g. an OpenError with ErrUnsupportedFormat
+}
+
+func (o *WatchOptions) setDefaults() {
+	if o.Debounce <= 0 {
+		o.Debounce = 500 * time.Millisecond
+	}
+	if o.StableInterval <= 0 {
+		o.StableInterval = 2 * time.Second
+	}
+}
+
+// accept reports whether the file at path must be analyzed
+func (o *WatchOptions) accept(path string) bool {
+	if len(o.Extensions) == 0 {
+		return true
+	}
+	ext := filepath.Ext(path)
+	for _, e := range o.Extensions {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package mediainfo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchMask are the inotify events watched on each directory
+const watchMask = unix.IN_CREATE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO |
+	unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_DELETE_SELF
+
+// Watcher analyzes the files created or modified under a directory tree.
+// A file is analyzed once its size and modification time are stable, results are delivered on Results.
+// libmediainfo must be loaded while the Watcher runs.
+type Watcher struct {
+	root    string
+	opts    WatchOptions
+	inform  func(string, ...Option) (Info, error)
+	results chan WatchResult
+
+	fd   int
+	wake [2]int // pipe used to interrupt poll on Close
+
+	mu      sync.Mutex
+	dirs    map[int]string // watch descriptor to directory
+	pending map[string]*pendingFile
+	closed  bool
+
+	done    chan struct{}
+	reading chan struct{} // closed when the read loop exits
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// pendingFile represents a file waiting for its size to be stable
+type pendingFile struct {
+	size    int64
+	modTime time.Time
+	checked bool // size and modTime were set by a previous check
+	timer   *time.Timer
+}
+
+// NewWatcher starts watching the tree under root
+func NewWatcher(root string, opts WatchOptions) (*Watcher, error) {
+	return newWatcher(root, opts, Inform)
+}
+
+func newWatcher(root string, opts WatchOptions, inform func(string, ...Option) (Info, error)) (*Watcher, error) {
+	opts.setDefaults()
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	w := &Watcher{
+		root:    root,
+		opts:    opts,
+		inform:  inform,
+		results: make(chan WatchResult),
+		fd:      fd,
+		dirs:    make(map[int]string),
+		pending: make(map[string]*pendingFile),
+		done:    make(chan struct{}),
+		reading: make(chan struct{}),
+	}
+	if err = unix.Pipe2(w.wake[:], unix.O_CLOEXEC|unix.O_NONBLOCK); err != nil {
+		unix.Close(fd)
+		return nil, os.NewSyscallError("pipe2", err)
+	}
+
+	w.mu.Lock()
+	err = w.addTree(root, opts.Existing)
+	w.mu.Unlock()
+	if err != nil {
+		w.closeFds()
+		return nil, err
+	}
+
+	go w.readEvents()
+	return w, nil
+}
+
+// Results returns the channel of the analyzed files, closed by Close
+func (w *Watcher) Results() <-chan WatchResult {
+	return w.results
+}
+
+// Close stops watching, waits for the running analyses and closes Results.
+// Results not received before Close are dropped.
+func (w *Watcher) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+		unix.Write(w.wake[1], []byte{0})
+		<-w.reading
+
+		w.mu.Lock()
+		w.closed = true
+		for path, p := range w.pending {
+			p.timer.Stop()
+			delete(w.pending, path)
+		}
+		w.mu.Unlock()
+
+		w.wg.Wait()
+		w.closeFds()
+		close(w.results)
+	})
+	return nil
+}
+
+func (w *Watcher) closeFds() {
+	unix.Close(w.fd)
+	unix.Close(w.wake[0])
+	unix.Close(w.wake[1])
+}
+
+// addTree watches dir and its subdirectories, queuing their files if queueFiles is set.
+// w.mu must be held.
+func (w *Watcher) addTree(dir string, queueFiles bool) error {
+	return filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			// removed while walking
+			return nil
+		}
+		if fi.IsDir() {
+			wd, err := unix.InotifyAddWatch(w.fd, path, watchMask)
+			if err != nil {
+				return &os.PathError{Op: "inotify_add_watch", Path: path, Err: err}
+			}
+			w.dirs[wd] = path
+			return nil
+		}
+		if queueFiles && fi.Mode().IsRegular() {
+			w.queue(path)
+		}
+		return nil
+	})
+}
+
+func (w *Watcher) readEvents() {
+	defer close(w.reading)
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}, {Fd: int32(w.wake[0]), Events: unix.POLLIN}}
+	for {
+		if _, err := unix.Poll(fds, -1); err != nil && err != unix.EINTR {
+			return
+		}
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+
+		n, err := unix.Read(w.fd, buf)
+		if err == unix.EAGAIN || err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		w.handleEvents(buf[:n])
+	}
+}
+
+// handleEvents handles the inotify events in buf
+func (w *Watcher) handleEvents(buf []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for off := 0; off+unix.SizeofInotifyEvent <= len(buf); {
+		ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+		name := buf[off+unix.SizeofInotifyEvent : off+unix.SizeofInotifyEvent+int(ev.Len)]
+		off += unix.SizeofInotifyEvent + int(ev.Len)
+
+		dir, ok := w.dirs[int(ev.Wd)]
+		if !ok {
+			continue
+		}
+		if ev.Mask&(unix.IN_DELETE_SELF|unix.IN_IGNORED) != 0 {
+			delete(w.dirs, int(ev.Wd))
+			continue
+		}
+		if i := bytes.IndexByte(name, 0); i >= 0 {
+			name = name[:i]
+		}
+		path := filepath.Join(dir, string(name))
+
+		switch {
+		case ev.Mask&unix.IN_ISDIR != 0:
+			if ev.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+				// files may have been created before the watch was added
+				w.addTree(path, true)
+			}
+		case ev.Mask&(unix.IN_DELETE|unix.IN_MOVED_FROM) != 0:
+			if p, ok := w.pending[path]; ok {
+				p.timer.Stop()
+				delete(w.pending, path)
+			}
+		default:
+			w.queue(path)
+		}
+	}
+}
+
+// queue schedules the check of path after the debounce delay, w.mu must be held
+func (w *Watcher) queue(path string) {
+	if w.closed || !w.opts.accept(path) {
+		return
+	}
+	if p, ok := w.pending[path]; ok {
+		p.timer.Reset(w.opts.Debounce)
+		return
+	}
+	w.pending[path] = &pendingFile{timer: time.AfterFunc(w.opts.Debounce, func() { w.check(path) })}
+}
+
+// check analyzes path if its size and modification time did not change since the previous check,
+// otherwise it checks again after StableInterval
+func (w *Watcher) check(path string) {
+	fi, err := os.Stat(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	p, ok := w.pending[path]
+	if w.closed || !ok {
+		return
+	}
+	if err != nil {
+		// removed or renamed, an event follows if it comes back
+		delete(w.pending, path)
+		return
+	}
+	if !p.checked || fi.Size() != p.size || !fi.ModTime().Equal(p.modTime) {
+		p.size, p.modTime, p.checked = fi.Size(), fi.ModTime(), true
+		p.timer.Reset(w.opts.StableInterval)
+		return
+	}
+
+	delete(w.pending, path)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		info, err := w.inform(path, w.opts.Options...)
+		select {
+		case w.results <- WatchResult{Path: path, Info: info, Err: err}:
+		case <-w.done:
+		}
+	}()
+}
@@ -0,0 +1,135 @@
+package mediainfo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeInform records the analyzed files
+type fakeInform struct {
+	mu    sync.Mutex
+	files []string
+}
+
+func (f *fakeInform) inform(path string, opts ...Option) (Info, error) {
+	f.mu.Lock()
+	f.files = append(f.files, path)
+	f.mu.Unlock()
+	return Info{General: General{CompleteName: path}}, nil
+}
+
+func receive(t *testing.T, w *Watcher) WatchResult {
+	t.Helper()
+	select {
+	case r := <-w.Results():
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result received")
+	}
+	return WatchResult{}
+}
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	existing := filepath.Join(dir, "existing.mkv")
+	if err := ioutil.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeInform{}
+	w, err := newWatcher(dir, WatchOptions{
+		Debounce:       20 * time.Millisecond,
+		StableInterval: 100 * time.Millisecond,
+		Extensions:     []string{".mkv"},
+		Existing:       true,
+	}, fake.inform)
+	if err != nil {
+		t.Fatalf("newWatcher() error = %v", err)
+	}
+
+	if r := receive(t, w); r.Path != existing {
+		t.Errorf("Path = %s, want %s", r.Path, existing)
+	}
+
+	// a file written in several parts is analyzed once, when complete
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	movie := filepath.Join(sub, "movie.mkv")
+	f, err := os.Create(movie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(sub, "movie.txt"), []byte("ignored"), 0644)
+	for i := 0; i < 5; i++ {
+		f.Write([]byte("data"))
+		time.Sleep(50 * time.Millisecond)
+	}
+	f.Close()
+
+	r := receive(t, w)
+	if r.Path != movie || r.Err != nil || r.Info.General.CompleteName != movie {
+		t.Errorf("result = %+v, want %s", r, movie)
+	}
+	if fi, _ := os.Stat(movie); fi.Size() != 20 {
+		t.Errorf("size = %d, want 20", fi.Size())
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, ok := <-w.Results(); ok {
+		t.Errorf("Results() not closed")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.files) != 2 {
+		t.Errorf("analyzed %v, want 2 files", fake.files)
+	}
+}
+
+func TestWatcher_CloseWithPending(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mediainfo-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fake := &fakeInform{}
+	w, err := newWatcher(dir, WatchOptions{StableInterval: time.Hour}, fake.inform)
+	if err != nil {
+		t.Fatalf("newWatcher() error = %v", err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "movie.mkv"), []byte("data"), 0644)
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		w.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() blocked")
+	}
+	if len(fake.files) != 0 {
+		t.Errorf("analyzed %v, want none", fake.files)
+	}
+}
+
+func TestNewWatcher_NotFound(t *testing.T) {
+	if _, err := NewWatcher(filepath.Join(os.TempDir(), "mediainfo-not-found"), WatchOptions{}); !os.IsNotExist(err) {
+		t.Errorf("NewWatcher() error = %v, want not exist", err)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+// +build !linux
+
+package mediainfo
+
+// Watcher analyzes the files created or modified under a directory tree, only supported on Linux
+type Watcher struct{}
+
+// NewWatcher returns ErrWatchNotSupported, watching needs inotify
+func NewWatcher(root string, opts WatchOptions) (*Watcher, error) {
+	return nil, ErrWatchNotSupported
+}
+
+// Results returns nil
+func (w *Watcher) Results() <-chan WatchResult {
+	return nil
+}
+
+// Close does nothing
+func (w *Watcher) Close() error {
+	return nil
+}
@@ -0,0 +1,203 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wavMaxChunk is the size limit of the fmt, bext and LIST chunks
+const wavMaxChunk = 16 << 20
+
+// waveFormats are the Format of the WAVE format tags
+var waveFormats = map[uint16]string{
+	0x0001: "PCM",
+	0x0002: "ADPCM",
+	0x0003: "PCM",
+	0x0006: "A-Law",
+	0x0007: "Mu-Law",
+	0x0050: "MPEG Audio",
+	0x0055: "MPEG Audio",
+	0x00FF: "AAC",
+	0x0161: "WMA",
+	0x0162: "WMA",
+	0x2000: "AC-3",
+	0x2001: "DTS",
+}
+
+// waveFormatExtensible is the format tag of WAVEFORMATEXTENSIBLE, the real one is in the sub format GUID
+const waveFormatExtensible = 0xFFFE
+
+// riffInfoTags maps LIST INFO chunks to Vorbis comment names, see setTag
+var riffInfoTags = map[string]string{
+	"INAM": "TITLE",
+	"IART": "ARTIST",
+	"IPRD": "ALBUM",
+	"IGNR": "GENRE",
+	"ICRD": "DATE",
+	"ICMT": "COMMENT",
+	"ICOP": "COPYRIGHT",
+	"IPRT": "TRACKNUMBER",
+	"ITRK": "TRACKNUMBER",
+}
+
+// probeWAV reads a RIFF WAVE or RF64 file, with the Broadcast Wave (bext) and LIST INFO metadata
+func probeWAV(r io.ReaderAt, size int64) (Info, error) {
+	h, err := readAt(r, 0, 12)
+	if err != nil || (string(h[:4]) != "RIFF" && string(h[:4]) != "RF64") || string(h[8:12]) != "WAVE" {
+		return Info{}, errNotAudioFormat
+	}
+
+	var (
+		a        Audio
+		tags     Tags
+		encoded  time.Time
+		software string
+		byteRate uint
+		align    uint  // bytes per sample of all channels
+		dataSize int64 = -1
+		ds64Data int64 = -1
+		hasFmt   bool
+	)
+	for off := int64(12); off+8 <= size; {
+		ch, err := readAt(r, off, 8)
+		if err != nil {
+			return Info{}, err
+		}
+		id, n := string(ch[:4]), int64(binary.LittleEndian.Uint32(ch[4:]))
+		off += 8
+		switch id {
+		case "ds64":
+			b, err := readAt(r, off, 16)
+			if err != nil {
+				return Info{}, err
+			}
+			ds64Data = int64(binary.LittleEndian.Uint64(b[8:]))
+		case "fmt ":
+			b, err := readChunk(r, off, n, size, wavMaxChunk)
+			if err != nil || n < 16 {
+				return Info{}, io.ErrUnexpectedEOF
+			}
+			a, byteRate, align = waveFormatAudio(b)
+			hasFmt = true
+		case "data":
+			dataSize = n
+			if n == 0xFFFFFFFF && ds64Data >= 0 {
+				dataSize = ds64Data
+			}
+			if off+dataSize > size {
+				dataSize = size - off
+			}
+			n = dataSize
+		case "bext":
+			b, err := readChunk(r, off, n, size, wavMaxChunk)
+			if err != nil {
+				return Info{}, err
+			}
+			encoded = broadcastExtension(b, &tags)
+		case "LIST":
+			b, err := readChunk(r, off, n, size, wavMaxChunk)
+			if err != nil {
+				return Info{}, err
+			}
+			if s := riffInfo(b, &tags); s != "" {
+				software = s
+			}
+		}
+		// chunks are word aligned
+		off += n + n&1
+	}
+	if !hasFmt || dataSize < 0 {
+		return Info{}, io.ErrUnexpectedEOF
+	}
+
+	a.StreamSize = uint(dataSize)
+	if byteRate > 0 {
+		a.BitRate = float32(byteRate * 8)
+		a.Duration = float32(float64(dataSize) / float64(byteRate))
+	}
+	if a.Format == "PCM" && align > 0 {
+		a.SamplingCount = uint(dataSize / int64(align))
+	}
+	info := newAudioInfo("Wave", size, a, tags)
+	info.General.EncodedDate = encoded
+	info.General.EncodedApplication = software
+	return info, nil
+}
+
+// waveFormatAudio decodes a WAVEFORMATEX(TENSIBLE) fmt chunk, returning the average byte rate and the block alignment
+func waveFormatAudio(b []byte) (a Audio, byteRate, align uint) {
+	tag := binary.LittleEndian.Uint16(b)
+	if tag == waveFormatExtensible && len(b) >= 26 {
+		// the sub format GUID starts with the format tag
+		tag = binary.LittleEndian.Uint16(b[24:])
+	}
+	a = Audio{
+		Format:          waveFormats[tag],
+		CodecID:         strings.ToUpper(strconv.FormatUint(uint64(tag), 16)),
+		Channels:        uint(binary.LittleEndian.Uint16(b[2:])),
+		SamplingRate:    uint(binary.LittleEndian.Uint32(b[4:])),
+		BitDepth:        uint(binary.LittleEndian.Uint16(b[14:])),
+		CompressionMode: "Lossy",
+	}
+	switch a.Format {
+	case "PCM":
+		a.CompressionMode = "Lossless"
+		if tag == 3 {
+			a.FormatProfile = "Float"
+		}
+	case "MPEG Audio", "AC-3", "DTS":
+		// the bit depth of a compressed stream is meaningless
+		a.BitDepth = 0
+	}
+	return a, uint(binary.LittleEndian.Uint32(b[8:])), uint(binary.LittleEndian.Uint16(b[12:]))
+}
+
+// broadcastExtension reads the bext chunk b into tags, returning the origination date
+func broadcastExtension(b []byte, tags *Tags) time.Time {
+	if len(b) < 338 {
+		return time.Time{}
+	}
+	field := func(b []byte) string {
+		return strings.TrimSpace(latin1(bytes.TrimRight(b, "\x00")))
+	}
+	// Description 256, Originator 32, OriginatorReference 32, OriginationDate 10, OriginationTime 8
+	tags.Description = field(b[:256])
+	if originator := field(b[256:288]); originator != "" {
+		setTag(tags, "ORIGINATOR", originator)
+	}
+	date, clock := field(b[320:330]), field(b[330:338])
+	// the separators are free, e.g. 2021-03-04 or 2021:03:04
+	t, _ := time.Parse("2006-01-02 15-04-05", strings.NewReplacer(":", "-", "/", "-", ".", "-").Replace(date+" "+clock))
+	return t
+}
+
+// riffInfo reads a LIST INFO chunk b into tags, returning the software (ISFT), other lists are ignored
+func riffInfo(b []byte, tags *Tags) (software string) {
+	if len(b) < 4 || string(b[:4]) != "INFO" {
+		return ""
+	}
+	for b = b[4:]; len(b) >= 8; {
+		// sizes are compared before the int conversion, negative on 32-bit platforms
+		id, size := string(b[:4]), binary.LittleEndian.Uint32(b[4:])
+		if int64(size) > int64(len(b)-8) {
+			break
+		}
+		n := int(size)
+		v := latin1(bytes.TrimRight(b[8:8+n], "\x00"))
+		if name, ok := riffInfoTags[id]; ok {
+			setTag(tags, name, v)
+		} else if id == "ISFT" {
+			software = v
+		}
+		n += n & 1
+		if 8+n > len(b) {
+			break
+		}
+		b = b[8+n:]
+	}
+	return software
+}